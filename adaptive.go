@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"math"
+	"runtime"
+	"time"
+)
+
+// tapCost is the estimated time needed to accumulate a single filter tap
+// of one pixel on one processor.
+var tapCost = 2 * time.Nanosecond
+
+// ResizeAdaptiveQuality scales an image like Resize, choosing the best
+// interpolation function whose estimated run time fits into budget.
+// Lanczos3, Lanczos2, Bilinear and NearestNeighbor are tried in this order,
+// NearestNeighbor is used if no other function fits.
+// The estimate is a heuristic based on the number of filter taps and is
+// not a guarantee.
+func ResizeAdaptiveQuality(width, height uint, img image.Image, budget time.Duration) image.Image {
+	return Resize(width, height, img, chooseInterpolation(width, height, img.Bounds(), budget))
+}
+
+// chooseInterpolation returns the best interpolation function for which
+// estimateResize stays within budget.
+func chooseInterpolation(width, height uint, bounds image.Rectangle, budget time.Duration) InterpolationFunction {
+	for _, interp := range []InterpolationFunction{Lanczos3, Lanczos2, Bilinear} {
+		if estimateResize(width, height, bounds, interp) <= budget {
+			return interp
+		}
+	}
+	return NearestNeighbor
+}
+
+// estimateResize estimates the run time of Resize.
+func estimateResize(width, height uint, bounds image.Rectangle, interp InterpolationFunction) time.Duration {
+	width, height, scaleX, scaleY := scaledSize(width, height, bounds.Dx(), bounds.Dy())
+
+	taps, _ := interp.kernel()
+	tapsX := float64(taps) * math.Max(math.Ceil(blur*scaleX), 1)
+	tapsY := float64(taps) * math.Max(math.Ceil(blur*scaleY), 1)
+
+	// The first pass filters every source row, the second every output row.
+	ops := float64(bounds.Dy())*float64(width)*tapsX + float64(width)*float64(height)*tapsY
+	return time.Duration(ops * float64(tapCost) / float64(runtime.GOMAXPROCS(0)))
+}
@@ -0,0 +1,32 @@
+package resize
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+func Test_ChooseInterpolation(t *testing.T) {
+	bounds := image.Rect(0, 0, 8000, 6000)
+
+	if interp := chooseInterpolation(1920, 0, bounds, time.Nanosecond); interp != NearestNeighbor {
+		t.Errorf("tight budget: want NearestNeighbor, got %v", interp)
+	}
+	if interp := chooseInterpolation(1920, 0, bounds, time.Hour); interp != Lanczos3 {
+		t.Errorf("generous budget: want Lanczos3, got %v", interp)
+	}
+
+	// A budget between the estimates selects the cheaper function.
+	budget := (estimateResize(1920, 0, bounds, Lanczos2) + estimateResize(1920, 0, bounds, Bilinear)) / 2
+	if interp := chooseInterpolation(1920, 0, bounds, budget); interp != Bilinear {
+		t.Errorf("want Bilinear, got %v", interp)
+	}
+}
+
+func Test_ResizeAdaptiveQuality(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 40, 40))
+	m := ResizeAdaptiveQuality(10, 0, img, time.Second)
+	if m.Bounds() != image.Rect(0, 0, 10, 10) {
+		t.Errorf("want bounds %v, got %v", image.Rect(0, 0, 10, 10), m.Bounds())
+	}
+}
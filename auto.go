@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// BandedThreshold is the number of source pixels above which ResizeAuto
+// computes the result in bands of bounded memory, like ResizeTiled, instead
+// of using the faster single buffer path of Resize.
+var BandedThreshold = 4096 * 4096
+
+// ResizeAuto scales an image like Resize, but passes sources with more than
+// BandedThreshold pixels to ResizeTiled, which gives the same pixels with
+// the temporary image of one band only.
+func ResizeAuto(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	if b.Dx()*b.Dy() <= BandedThreshold {
+		return Resize(width, height, img, interp)
+	}
+	return ResizeTiled(width, height, img, interp)
+}
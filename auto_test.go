@@ -0,0 +1,52 @@
+// +build go1.7
+
+package resize
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func Test_ResizeAuto(t *testing.T) {
+	defer func(threshold int) { BandedThreshold = threshold }(BandedThreshold)
+
+	rgba := image.NewRGBA(image.Rect(0, 0, 120, 90))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	for _, img := range []image.Image{gradientGray(120, 90), rgba} {
+		want := Resize(50, 140, img, Lanczos3)
+		// Sources below and above the threshold.
+		for _, threshold := range []int{120 * 90, 120*90 - 1} {
+			BandedThreshold = threshold
+			got := ResizeAuto(50, 140, img, Lanczos3)
+			if reflect.TypeOf(got) != reflect.TypeOf(want) || got.Bounds() != want.Bounds() {
+				t.Fatalf("%T, threshold %d: want %T %v, got %T %v", img, threshold, want, want.Bounds(), got, got.Bounds())
+			}
+			for y := 0; y < 140; y++ {
+				for x := 0; x < 50; x++ {
+					if g, w := got.At(x, y), want.At(x, y); g != w {
+						t.Fatalf("%T, threshold %d: pixel (%d,%d): want %v, got %v", img, threshold, x, y, w, g)
+					}
+				}
+			}
+		}
+	}
+}
+
+func benchAuto(b *testing.B, threshold int) {
+	defer func(threshold int) { BandedThreshold = threshold }(BandedThreshold)
+	BandedThreshold = threshold
+
+	img := gradientGray(2000, 2000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ResizeAuto(1000, 1000, img, Lanczos3)
+	}
+}
+
+func Benchmark_ResizeAutoSingleBuffer(b *testing.B) { benchAuto(b, 2000*2000) }
+
+func Benchmark_ResizeAutoBanded(b *testing.B) { benchAuto(b, 2000*2000-1) }
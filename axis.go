@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// identity8 reports whether 8-bit weights copy every pixel unchanged, i.e.
+// every result pixel has a single tap of full weight on the source pixel at
+// the same index. This is the case for an axis whose size does not change.
+func identity8(coeffs []int16, offset []int, filterLength int) bool {
+	for y := range offset {
+		for i := 0; i < filterLength; i++ {
+			c := coeffs[y*filterLength+i]
+			if offset[y]+i == y {
+				if c != 1<<weightBits8 {
+					return false
+				}
+			} else if c != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// identity16 is identity8 for 16-bit weights, which are normalized by their
+// sum, so the single tap may have any weight.
+func identity16(coeffs []int32, offset []int, filterLength int) bool {
+	for y := range offset {
+		for i := 0; i < filterLength; i++ {
+			c := coeffs[y*filterLength+i]
+			if (offset[y]+i == y) != (c != 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// transpose writes the pixels of in, which have bpp bytes each, to out with
+// rows and columns swapped. out is a slice of the transposed image as
+// returned by makeSlice, with its rows at r.
+func transpose(in []uint8, inStride int, out []uint8, outStride int, r image.Rectangle, bpp int) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := out[(y-r.Min.Y)*outStride:]
+		xi := r.Min.X*inStride + y*bpp
+		for xo := 0; xo < r.Dx()*bpp; xo += bpp {
+			for i := 0; i < bpp; i++ {
+				row[xo+i] = in[xi+i]
+			}
+			xi += inStride
+		}
+	}
+}
+
+// applyOneAxis scales img like apply if the weights of one axis copy every
+// pixel unchanged, e.g. when only the width or only the height changes. The
+// filter pass of that axis is replaced by a transposition, which gives the
+// same pixels for half the filtering. It returns nil if both axes are
+// filtered and for images that the first pass converts to another type.
+func (p *ResizePlan) applyOneAxis(img image.Image) image.Image {
+	var bpp int
+	var wide bool
+	var pix func(m image.Image) ([]uint8, int)
+	var newImage func(r image.Rectangle) imageWithSubImage
+	var pass func(in, out image.Image, scale float64, coeffs8 []int16, coeffs16 []int32, offset []int, filterLength int)
+	switch img.(type) {
+	case *image.RGBA:
+		bpp = 4
+		pix = func(m image.Image) ([]uint8, int) { return m.(*image.RGBA).Pix, m.(*image.RGBA).Stride }
+		newImage = func(r image.Rectangle) imageWithSubImage { return image.NewRGBA(r) }
+		pass = func(in, out image.Image, scale float64, coeffs8 []int16, coeffs16 []int32, offset []int, filterLength int) {
+			resizeRGBA(in.(*image.RGBA), out.(*image.RGBA), scale, coeffs8, offset, filterLength)
+		}
+	case *image.Gray:
+		bpp = 1
+		pix = func(m image.Image) ([]uint8, int) { return m.(*image.Gray).Pix, m.(*image.Gray).Stride }
+		newImage = func(r image.Rectangle) imageWithSubImage { return image.NewGray(r) }
+		pass = func(in, out image.Image, scale float64, coeffs8 []int16, coeffs16 []int32, offset []int, filterLength int) {
+			resizeGray(in.(*image.Gray), out.(*image.Gray), scale, coeffs8, offset, filterLength)
+		}
+	case *image.RGBA64:
+		wide = true
+		bpp = 8
+		pix = func(m image.Image) ([]uint8, int) { return m.(*image.RGBA64).Pix, m.(*image.RGBA64).Stride }
+		newImage = func(r image.Rectangle) imageWithSubImage { return image.NewRGBA64(r) }
+		pass = func(in, out image.Image, scale float64, coeffs8 []int16, coeffs16 []int32, offset []int, filterLength int) {
+			resizeRGBA64(in.(*image.RGBA64), out.(*image.RGBA64), scale, coeffs16, offset, filterLength)
+		}
+	case *image.Gray16:
+		wide = true
+		bpp = 2
+		pix = func(m image.Image) ([]uint8, int) { return m.(*image.Gray16).Pix, m.(*image.Gray16).Stride }
+		newImage = func(r image.Rectangle) imageWithSubImage { return image.NewGray16(r) }
+		pass = func(in, out image.Image, scale float64, coeffs8 []int16, coeffs16 []int32, offset []int, filterLength int) {
+			resizeGray16(in.(*image.Gray16), out.(*image.Gray16), scale, coeffs16, offset, filterLength)
+		}
+	default:
+		return nil
+	}
+
+	// weights returns the weights of the horizontal or the vertical pass
+	// in the precision of img and whether they copy every pixel.
+	weights := func(vertical bool) (coeffs8 []int16, coeffs16 []int32, offset []int, filterLength int, same bool) {
+		if wide {
+			if vertical {
+				coeffs16, offset, filterLength = p.weights16Y()
+			} else {
+				coeffs16, offset, filterLength = p.weights16X()
+			}
+			return nil, coeffs16, offset, filterLength, identity16(coeffs16, offset, filterLength)
+		}
+		if vertical {
+			coeffs8, offset, filterLength = p.weights8Y()
+		} else {
+			coeffs8, offset, filterLength = p.weights8X()
+		}
+		return coeffs8, nil, offset, filterLength, identity8(coeffs8, offset, filterLength)
+	}
+	x8, x16, xOffset, xLength, sameX := weights(false)
+	y8, y16, yOffset, yLength, sameY := weights(true)
+	if !sameX && !sameY {
+		return nil
+	}
+
+	b := img.Bounds()
+	temp := newImage(image.Rect(0, 0, b.Dy(), p.width))
+	result := newImage(image.Rect(0, 0, p.width, p.height))
+
+	// horizontal filter or transposition, results in transposed temporary
+	// image
+	p.runPass(0, temp, func(slice image.Image) {
+		if sameX {
+			in, inStride := pix(img)
+			out, outStride := pix(slice)
+			transpose(in, inStride, out, outStride, slice.Bounds(), bpp)
+		} else {
+			pass(img, slice, p.scaleX, x8, x16, xOffset, xLength)
+		}
+	})
+
+	// horizontal filter or transposition on transposed image, result is not
+	// transposed
+	p.runPass(1, result, func(slice image.Image) {
+		if sameY {
+			in, inStride := pix(temp)
+			out, outStride := pix(slice)
+			transpose(in, inStride, out, outStride, slice.Bounds(), bpp)
+		} else {
+			pass(temp, slice, p.scaleY, y8, y16, yOffset, yLength)
+		}
+	})
+	return result
+}
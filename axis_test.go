@@ -0,0 +1,67 @@
+package resize
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// twoPass scales an RGBA or Gray16 image with both filter passes.
+func twoPass(p *ResizePlan, img image.Image) image.Image {
+	b := img.Bounds()
+	switch input := img.(type) {
+	case *image.RGBA:
+		temp := image.NewRGBA(image.Rect(0, 0, b.Dy(), p.width))
+		result := image.NewRGBA(image.Rect(0, 0, p.width, p.height))
+		coeffs, offset, filterLength := p.weights8X()
+		resizeRGBA(input, temp, p.scaleX, coeffs, offset, filterLength)
+		coeffs, offset, filterLength = p.weights8Y()
+		resizeRGBA(temp, result, p.scaleY, coeffs, offset, filterLength)
+		return result
+	case *image.Gray16:
+		temp := image.NewGray16(image.Rect(0, 0, b.Dy(), p.width))
+		result := image.NewGray16(image.Rect(0, 0, p.width, p.height))
+		coeffs, offset, filterLength := p.weights16X()
+		resizeGray16(input, temp, p.scaleX, coeffs, offset, filterLength)
+		coeffs, offset, filterLength = p.weights16Y()
+		resizeGray16(temp, result, p.scaleY, coeffs, offset, filterLength)
+		return result
+	}
+	return nil
+}
+
+func Test_ResizeOneAxis(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i*7 + i/11)
+	}
+	gray16 := image.NewGray16(image.Rect(3, 5, 63, 45))
+	for i := range gray16.Pix {
+		gray16.Pix[i] = uint8(i * 13)
+	}
+
+	for _, img := range []image.Image{rgba, gray16} {
+		for _, interp := range []InterpolationFunction{Bilinear, Bicubic, Lanczos2, Lanczos3} {
+			for _, size := range []image.Point{{25, 40}, {150, 40}, {60, 17}, {60, 90}} {
+				p := newPlan(60, 40, size.X, size.Y, 60/float64(size.X), 40/float64(size.Y), interp)
+				m := p.applyOneAxis(img)
+				if m == nil {
+					t.Fatalf("%T %v interp %v: no pass skipped", img, size, interp)
+				}
+				if want := twoPass(p, img); !reflect.DeepEqual(m, want) {
+					t.Errorf("%T %v interp %v: differs from both passes", img, size, interp)
+				}
+			}
+		}
+	}
+
+	p := newPlan(60, 40, 30, 20, 2, 2, Bilinear)
+	if p.applyOneAxis(rgba) != nil {
+		t.Error("pass skipped although both sizes change")
+	}
+	// MitchellNetravali blurs even if the size does not change.
+	p = newPlan(60, 40, 30, 40, 2, 1, MitchellNetravali)
+	if p.applyOneAxis(rgba) != nil {
+		t.Error("pass skipped for MitchellNetravali")
+	}
+}
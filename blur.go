@@ -0,0 +1,43 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// Blur blurs an image with a box filter that averages the pixels within
+// radius pixels horizontally and vertically, e.g. as a prefilter against
+// aliasing before a large reduction. Pixels beyond the border are
+// replicated. With a radius of 0, img is returned; otherwise the result is
+// an *image.RGBA64 with the size of img and its origin at (0,0).
+func Blur(img image.Image, radius uint) image.Image {
+	b := img.Bounds()
+	if radius == 0 || b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	in := newFloatImageFromImage(img)
+	out := newFloatImage(in.Rect, 4)
+	temp := newFloatImage(image.Rect(0, 0, b.Dy(), b.Dx()), 4)
+	// The Area kernel stretched to 2*radius+1 pixels.
+	width := float64(2*radius + 1)
+	blurFloat(in, out, temp, 2*int(radius+1), func(x float64) float64 {
+		return box(x / width)
+	})
+	return out.RGBA64()
+}
@@ -0,0 +1,52 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_Blur(t *testing.T) {
+	solid := image.NewNRGBA(image.Rect(2, 3, 22, 13))
+	c := color.NRGBA{0x12, 0x34, 0x56, 0x80}
+	for y := 3; y < 13; y++ {
+		for x := 2; x < 22; x++ {
+			solid.SetNRGBA(x, y, c)
+		}
+	}
+	m := Blur(solid, 3)
+	want := color.RGBA64Model.Convert(c)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			if g := m.At(x, y); g != want {
+				t.Fatalf("solid color changed at (%d,%d): want %v, got %v", x, y, want, g)
+			}
+		}
+	}
+
+	point := image.NewGray16(image.Rect(0, 0, 15, 15))
+	point.SetGray16(7, 7, color.Gray16{0xffff})
+	m = Blur(point, 2)
+	at := func(x, y int) uint32 {
+		r, _, _, _ := m.At(x, y).RGBA()
+		return r
+	}
+	if v := at(7, 7); v != 0xffff/25 {
+		t.Errorf("center: want %#x, got %#x", 0xffff/25, v)
+	}
+	for y := 0; y < 15; y++ {
+		for x := 0; x < 15; x++ {
+			v := at(x, y)
+			if v != at(14-x, y) || v != at(x, 14-y) || v != at(y, x) {
+				t.Fatalf("spread not symmetric at (%d,%d)", x, y)
+			}
+			if inside := absDiff(uint32(x), 7) <= 2 && absDiff(uint32(y), 7) <= 2; inside != (v != 0) {
+				t.Errorf("(%d,%d): got %#x", x, y, v)
+			}
+		}
+	}
+
+	if Blur(point, 0) != image.Image(point) {
+		t.Error("radius 0 changes the image")
+	}
+}
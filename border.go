@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+// BorderMode selects how a filter samples pixels outside of the image.
+type BorderMode int
+
+// BorderMode constants
+const (
+	// Replicate repeats the pixels at the border of the image.
+	Replicate BorderMode = iota
+	// Renormalize ignores pixels outside of the image and scales the
+	// weights of the remaining taps so that they sum to one.
+	Renormalize
+	// Wrap continues the image with its opposite border, as if it were
+	// tiled.
+	Wrap
+	// MirrorEdge reflects the image at its border and repeats the border
+	// pixel, i.e. abcd becomes dcba|abcd|dcba. This is the "reflect" mode
+	// of SciPy and BORDER_REFLECT of OpenCV.
+	MirrorEdge
+	// MirrorNoEdge reflects the image about its border pixel, which is not
+	// repeated, i.e. abcd becomes dcb|abcd|cba. This is the "mirror" mode
+	// of SciPy and BORDER_REFLECT_101 of OpenCV.
+	MirrorNoEdge
+)
+
+// borderIndex returns the source pixel of every tap of a filter with the
+// given offsets for an image of size pixels, mapped into the image
+// according to border. Taps that are ignored get a coefficient of 0.
+func borderIndex(coeffs []float32, offset []int, filterLength, size int, border BorderMode) []int {
+	index := make([]int, len(coeffs))
+	for y, start := range offset {
+		ci := y * filterLength
+		for i := 0; i < filterLength; i++ {
+			xi := start + i
+			if xi < 0 || xi >= size {
+				switch border {
+				case Renormalize:
+					coeffs[ci+i] = 0
+					xi = 0
+				case Wrap:
+					xi = wrapBorder(xi, size)
+				case MirrorEdge:
+					xi = mirrorEdgeBorder(xi, size)
+				case MirrorNoEdge:
+					xi = mirrorNoEdgeBorder(xi, size)
+				default:
+					xi = replicateBorder(xi, size)
+				}
+			}
+			index[ci+i] = xi
+		}
+	}
+	return index
+}
+
+// replicateBorder clamps x to [0,size).
+func replicateBorder(x, size int) int {
+	if x < 0 {
+		return 0
+	}
+	if x >= size {
+		return size - 1
+	}
+	return x
+}
+
+// wrapBorder maps x periodically into [0,size).
+func wrapBorder(x, size int) int {
+	x %= size
+	if x < 0 {
+		x += size
+	}
+	return x
+}
+
+// mirrorEdgeBorder reflects x into [0,size), repeating the border pixels.
+func mirrorEdgeBorder(x, size int) int {
+	x = wrapBorder(x, 2*size)
+	if x >= size {
+		x = 2*size - 1 - x
+	}
+	return x
+}
+
+// mirrorNoEdgeBorder reflects x into [0,size) about the border pixels.
+func mirrorNoEdgeBorder(x, size int) int {
+	if size == 1 {
+		return 0
+	}
+	x = wrapBorder(x, 2*size-2)
+	if x >= size {
+		x = 2*size - 2 - x
+	}
+	return x
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeByteSwapped16 scales an *image.Gray16, *image.RGBA64 or
+// *image.NRGBA64 whose samples were loaded in little-endian instead of the
+// big-endian order of the image package. The samples are swapped in a copy
+// of the pixels, which needs as much memory as img, and the copy is scaled
+// like Resize, so the result holds correctly ordered samples. img is not
+// modified. Other images are scaled like Resize.
+func ResizeByteSwapped16(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	switch input := img.(type) {
+	case *image.Gray16:
+		m := *input
+		m.Pix = swapBytes16(input.Pix)
+		img = &m
+	case *image.RGBA64:
+		m := *input
+		m.Pix = swapBytes16(input.Pix)
+		img = &m
+	case *image.NRGBA64:
+		m := *input
+		m.Pix = swapBytes16(input.Pix)
+		img = &m
+	}
+	return Resize(width, height, img, interp)
+}
+
+// swapBytes16 returns a copy of pix with the bytes of every 16-bit sample
+// swapped.
+func swapBytes16(pix []uint8) []uint8 {
+	swapped := make([]uint8, len(pix))
+	for i := 0; i+1 < len(pix); i += 2 {
+		swapped[i+0] = pix[i+1]
+		swapped[i+1] = pix[i+0]
+	}
+	return swapped
+}
@@ -0,0 +1,44 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func Test_ResizeByteSwapped16(t *testing.T) {
+	gray := image.NewGray16(image.Rect(0, 0, 30, 20))
+	rgba := image.NewRGBA64(image.Rect(0, 0, 30, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			gray.SetGray16(x, y, color.Gray16{uint16(x*2000 + y*7)})
+			rgba.SetRGBA64(x, y, color.RGBA64{uint16(x * 2000), uint16(y*3000 + 0x12), 0x1234, 0xffff})
+		}
+	}
+
+	for _, img := range []image.Image{gray, rgba} {
+		var swapped image.Image
+		switch m := img.(type) {
+		case *image.Gray16:
+			c := *m
+			c.Pix = swapBytes16(m.Pix)
+			swapped = &c
+		case *image.RGBA64:
+			c := *m
+			c.Pix = swapBytes16(m.Pix)
+			swapped = &c
+		}
+		orig := reflect.ValueOf(swapped).Elem().FieldByName("Pix").Bytes()
+		saved := append([]uint8(nil), orig...)
+
+		want := Resize(12, 9, img, Lanczos3)
+		got := ResizeByteSwapped16(12, 9, swapped, Lanczos3)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%T: byte swapped source gives a different result", img)
+		}
+		if !reflect.DeepEqual(orig, saved) {
+			t.Errorf("%T: source modified", img)
+		}
+	}
+}
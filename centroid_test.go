@@ -0,0 +1,51 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// A single bright pixel has to stay at its geometric position. The centroid
+// is not exact as the kernel is sampled on the output grid, but the error
+// must not show a systematic offset.
+func Test_Centroid(t *testing.T) {
+	const (
+		background = 0x4000
+		srcSize    = 60
+	)
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		for _, dst := range []int{20, 24, 45, 90, 150} {
+			for _, p := range []image.Point{{17, 31}, {30, 30}, {41, 22}} {
+				img := image.NewGray16(image.Rect(0, 0, srcSize, srcSize))
+				for y := 0; y < srcSize; y++ {
+					for x := 0; x < srcSize; x++ {
+						img.SetGray16(x, y, color.Gray16{background})
+					}
+				}
+				img.SetGray16(p.X, p.Y, color.Gray16{0xc000})
+
+				out := Resize(uint(dst), uint(dst), img, interp).(*image.Gray16)
+				var sum, cx, cy float64
+				for y := 0; y < dst; y++ {
+					for x := 0; x < dst; x++ {
+						v := float64(out.Gray16At(x, y).Y) - background
+						sum += v
+						cx += v * float64(x)
+						cy += v * float64(y)
+					}
+				}
+				cx /= sum
+				cy /= sum
+
+				scale := float64(srcSize) / float64(dst)
+				wantX := (float64(p.X)+0.5)/scale - 0.5
+				wantY := (float64(p.Y)+0.5)/scale - 0.5
+				if math.Abs(cx-wantX) > 0.05 || math.Abs(cy-wantY) > 0.05 {
+					t.Errorf("%v %d %v: centroid (%.3f,%.3f), want (%.3f,%.3f)", interp, dst, p, cx, cy, wantX, wantY)
+				}
+			}
+		}
+	}
+}
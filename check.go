@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"errors"
+	"image"
+)
+
+// Errors returned by ResizeE.
+var (
+	ErrNilImage      = errors.New("resize: image is nil")
+	ErrEmptyImage    = errors.New("resize: image has no pixels")
+	ErrNoSize        = errors.New("resize: width and height are 0")
+	ErrInterpolation = errors.New("resize: unknown interpolation function")
+)
+
+// ResizeE scales an image like Resize, but checks its arguments first, e.g.
+// for untrusted input: it returns ErrNilImage for a nil image, ErrEmptyImage
+// for an image without pixels, ErrNoSize if both width and height are 0 and
+// ErrInterpolation if interp is neither a constant nor was returned by
+// Lanczos or CubicBC. Resize returns such images unchanged or, for an
+// unknown interpolation function, may panic.
+func ResizeE(width, height uint, img image.Image, interp InterpolationFunction) (image.Image, error) {
+	if img == nil {
+		return nil, ErrNilImage
+	}
+	if img.Bounds().Empty() {
+		return nil, ErrEmptyImage
+	}
+	if width == 0 && height == 0 {
+		return nil, ErrNoSize
+	}
+	if !interp.valid() {
+		return nil, ErrInterpolation
+	}
+	return Resize(width, height, img, interp), nil
+}
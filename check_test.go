@@ -0,0 +1,42 @@
+package resize
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_ResizeE(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 10))
+	tests := []struct {
+		width, height uint
+		img           image.Image
+		interp        InterpolationFunction
+		err           error
+	}{
+		{10, 5, nil, Bilinear, ErrNilImage},
+		{10, 5, image.NewGray(image.Rect(3, 3, 3, 8)), Bilinear, ErrEmptyImage},
+		{0, 0, img, Bilinear, ErrNoSize},
+		{10, 5, img, Area + 1, ErrInterpolation},
+		{10, 5, img, -1, ErrInterpolation},
+		{10, 5, img, cubicBase + (cubicSteps+1)*(cubicSteps+1), ErrInterpolation},
+		{10, 5, img, Bilinear, nil},
+		{10, 0, img, Lanczos(4), nil},
+		{0, 5, img, CubicBC(0, 0.5), nil},
+	}
+	for i, tt := range tests {
+		m, err := ResizeE(tt.width, tt.height, tt.img, tt.interp)
+		if err != tt.err {
+			t.Errorf("%d: want error %v, got %v", i, tt.err, err)
+			continue
+		}
+		if err != nil {
+			if m != nil {
+				t.Errorf("%d: want no image with error %v", i, err)
+			}
+			continue
+		}
+		if m.Bounds() != image.Rect(0, 0, 10, 5) {
+			t.Errorf("%d: want bounds %v, got %v", i, image.Rect(0, 0, 10, 5), m.Bounds())
+		}
+	}
+}
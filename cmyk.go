@@ -0,0 +1,55 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeCMYK scales a CMYK image without converting it to RGB. Pixels covered
+// by black ink only keep C=M=Y=0.
+func ResizeCMYK(width, height uint, img *image.CMYK, interp InterpolationFunction) *image.CMYK {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	in := newFloatImage(image.Rect(0, 0, b.Dx(), b.Dy()), 4)
+	for y := 0; y < b.Dy(); y++ {
+		i := img.PixOffset(b.Min.X, b.Min.Y+y)
+		o := in.PixOffset(0, y)
+		for x := 0; x < 4*b.Dx(); x++ {
+			in.Pix[o+x] = float32(img.Pix[i+x])
+		}
+	}
+
+	// Ink that is 0 in every tap sums to exactly 0, even with negative
+	// lobes, and negative results are clamped to 0.
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+
+	result := image.NewCMYK(image.Rect(0, 0, int(width), int(height)))
+	for i, v := range out.Pix {
+		if v > 0 {
+			result.Pix[i] = floatToUint8(v + 0.5)
+		}
+	}
+	return result
+}
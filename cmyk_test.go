@@ -0,0 +1,92 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeCMYKPureBlack(t *testing.T) {
+	// A black line and a cyan patch on white.
+	img := image.NewCMYK(image.Rect(0, 0, 60, 40))
+	for y := 0; y < 40; y++ {
+		img.SetCMYK(20, y, color.CMYK{0, 0, 0, 0xff})
+		img.SetCMYK(21, y, color.CMYK{0, 0, 0, 0x80})
+		img.SetCMYK(55, y, color.CMYK{0xff, 0, 0, 0})
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Bicubic, Lanczos3} {
+		m := ResizeCMYK(25, 0, img, interp)
+		if m.Bounds() != image.Rect(0, 0, 25, 17) {
+			t.Fatalf("%v: want bounds %v, got %v", interp, image.Rect(0, 0, 25, 17), m.Bounds())
+		}
+		var black uint8
+		for y := 0; y < 17; y++ {
+			for x := 0; x < 15; x++ {
+				c := m.CMYKAt(x, y)
+				if c.C != 0 || c.M != 0 || c.Y != 0 {
+					t.Fatalf("%v: (%d,%d): black line picked up color %v", interp, x, y, c)
+				}
+				if c.K > black {
+					black = c.K
+				}
+			}
+		}
+		if black == 0 {
+			t.Errorf("%v: black line is lost", interp)
+		}
+		if c := m.CMYKAt(23, 8); c.C == 0 {
+			t.Errorf("%v: cyan patch is lost: %v", interp, c)
+		}
+	}
+}
+
+func Test_ResizeFromCMYK(t *testing.T) {
+	img := image.NewCMYK(image.Rect(0, 0, 50, 30))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	rgba := image.NewRGBA(img.Rect)
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 50; x++ {
+			c := img.CMYKAt(x, y)
+			r, g, b := color.CMYKToRGB(c.C, c.M, c.Y, c.K)
+			rgba.SetRGBA(x, y, color.RGBA{r, g, b, 0xff})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		got, ok := Resize(20, 45, img, interp).(*image.RGBA)
+		if !ok {
+			t.Fatalf("want *image.RGBA, got %T", got)
+		}
+		want := Resize(20, 45, rgba, interp).(*image.RGBA)
+		if string(got.Pix) != string(want.Pix) {
+			t.Errorf("%v: result differs from the RGB conversion", interp)
+		}
+	}
+}
+
+// cmykImage hides the type of an *image.CMYK from Resize.
+type cmykImage struct {
+	*image.CMYK
+}
+
+func benchCMYK(b *testing.B, generic bool) {
+	m := image.NewCMYK(image.Rect(0, 0, 5000, 4000))
+	for i := range m.Pix {
+		m.Pix[i] = uint8(i * 7)
+	}
+	var img image.Image = m
+	if generic {
+		img = cmykImage{m}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Resize(1000, 0, img, Bilinear)
+	}
+}
+
+func Benchmark_ResizeCMYK(b *testing.B)        { benchCMYK(b, false) }
+func Benchmark_ResizeCMYKGeneric(b *testing.B) { benchCMYK(b, true) }
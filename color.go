@@ -0,0 +1,521 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// ResizeColorMatrix scales an image like Resize and transforms its
+// non-premultiplied colors with m, whose rows compute red, green and blue from
+// red, green, blue and a constant. The result is an *image.RGBA64.
+func ResizeColorMatrix(width, height uint, m [3][4]float32, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	out := newFloatImageFromImage(img)
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		out = resizeFloat(out, int(width), int(height), scaleX, scaleY, interp)
+	}
+	for i := 0; i < len(out.Pix); i += 4 {
+		a := out.Pix[i+3]
+		if a <= 0 {
+			out.Pix[i+0], out.Pix[i+1], out.Pix[i+2] = 0, 0, 0
+			continue
+		}
+		if a > 0xffff {
+			a = 0xffff
+		}
+		r, g, b := out.Pix[i+0]/a, out.Pix[i+1]/a, out.Pix[i+2]/a
+		for c := 0; c < 3; c++ {
+			v := m[c][0]*r + m[c][1]*g + m[c][2]*b + m[c][3]
+			switch {
+			case v < 0:
+				v = 0
+			case v > 1:
+				v = 1
+			}
+			out.Pix[i+c] = v * a
+		}
+		out.Pix[i+3] = a
+	}
+	return out.RGBA64()
+}
+
+// ResizeLogo scales an image with soft edges on a transparent background, e.g.
+// a logo, in premultiplied linear light with MitchellNetravali.
+func ResizeLogo(width, height uint, img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return image.NewNRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	// Trivial case: convert the input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		result := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(result, result.Bounds(), img, b.Min, draw.Src)
+		return result
+	}
+
+	out := resizeFloat(linearFloatImage(img), int(width), int(height), scaleX, scaleY, MitchellNetravali)
+	return out.linearNRGBA()
+}
+
+// linearFloatImage returns the colors of img in premultiplied linear light.
+func linearFloatImage(img image.Image) *floatImage {
+	in := newFloatImageFromImage(img)
+	for i := 0; i < len(in.Pix); i += 4 {
+		a := in.Pix[i+3]
+		if a == 0 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			in.Pix[i+c] = float32(srgbToLinear(float64(in.Pix[i+c]/a))) * a
+		}
+	}
+	return in
+}
+
+// linearNRGBA converts a floatImage in premultiplied linear light, as
+// returned by linearFloatImage, to sRGB colors with straight alpha.
+func (p *floatImage) linearNRGBA() *image.NRGBA {
+	result := image.NewNRGBA(p.Rect)
+	for i, o := 0, 0; i < len(p.Pix); i, o = i+4, o+4 {
+		a := p.Pix[i+3]
+		if a <= 0 {
+			continue
+		}
+		if a > 0xffff {
+			a = 0xffff
+		}
+		for c := 0; c < 3; c++ {
+			result.Pix[o+c] = floatToUint8(float32(linearToSRGB(float64(p.Pix[i+c]/a))*0xff) + 0.5)
+		}
+		result.Pix[o+3] = uint8(clampFloatUint16(a) >> 8)
+	}
+	return result
+}
+
+// ResizePremultRGBA scales an image like Resize with premultiplied colors and
+// always returns a new *image.RGBA.
+func ResizePremultRGBA(width, height uint, img image.Image, interp InterpolationFunction) *image.RGBA {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	out := newFloatImageFromImage(img)
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		if b.Dx() <= 0 || b.Dy() <= 0 {
+			return image.NewRGBA(image.Rect(0, 0, 0, 0))
+		}
+		out = resizeFloat(out, int(width), int(height), scaleX, scaleY, interp)
+	}
+
+	result := image.NewRGBA(out.Rect)
+	// Rounding is monotonic, so colors never exceed alpha.
+	packRGBA(result.Pix, out.Pix)
+	return result
+}
+
+// ResizePremultLinear scales a premultiplied image in premultiplied linear
+// light and always returns a new premultiplied image.
+func ResizePremultLinear(width, height uint, img *image.RGBA, interp InterpolationFunction) *image.RGBA {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	out := linearFloatImage(img)
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		if b.Dx() <= 0 || b.Dy() <= 0 {
+			return image.NewRGBA(image.Rect(0, 0, 0, 0))
+		}
+		out = resizeFloat(out, int(width), int(height), scaleX, scaleY, interp)
+	}
+
+	out.delinearize()
+	result := image.NewRGBA(out.Rect)
+	packRGBA(result.Pix, out.Pix)
+	return result
+}
+
+// delinearize converts a floatImage in premultiplied linear light, as
+// returned by linearFloatImage, to premultiplied sRGB in place. Alpha is
+// clamped to [0,65535].
+func (p *floatImage) delinearize() {
+	for i := 0; i < len(p.Pix); i += 4 {
+		a := p.Pix[i+3]
+		if a <= 0 {
+			p.Pix[i+0], p.Pix[i+1], p.Pix[i+2], p.Pix[i+3] = 0, 0, 0, 0
+			continue
+		}
+		if a > 0xffff {
+			a = 0xffff
+		}
+		for c := 0; c < 3; c++ {
+			p.Pix[i+c] = float32(linearToSRGB(float64(p.Pix[i+c]/a))) * a
+		}
+		p.Pix[i+3] = a
+	}
+}
+
+// srgbToLinear converts a sRGB value in [0,1] to linear light.
+// Values outside of [0,1] are clamped.
+func srgbToLinear(v float64) float64 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 1
+	case v <= 0.04045:
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB converts a linear light value in [0,1] to sRGB.
+// Values outside of [0,1] are clamped.
+func linearToSRGB(v float64) float64 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 1
+	case v <= 0.0031308:
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// ResizeHSV scales an image like Resize, but interpolates in the HSV color
+// space. This avoids the dull intermediate colors that interpolating red,
+// green and blue produces between saturated colors of different hue.
+//
+// Hue is circular, so it is interpolated as the mean of unit vectors at the
+// hue angle, weighted with saturation: colors blend along the shorter arc of
+// the hue circle and grays, whose hue is undefined, do not pull the hue.
+// Saturation and value are interpolated linearly, and all channels are
+// weighted with alpha. The result is an *image.RGBA64.
+func ResizeHSV(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	src := newFloatImageFromImage(img)
+	in := newFloatImage(src.Rect, 5)
+	for i, o := 0, 0; i < len(src.Pix); i, o = i+4, o+5 {
+		a := src.Pix[i+3]
+		if a == 0 {
+			continue
+		}
+		h, s, v := rgbToHSV(float64(src.Pix[i+0]/a), float64(src.Pix[i+1]/a), float64(src.Pix[i+2]/a))
+		sin, cos := math.Sincos(h * math.Pi / 180)
+		in.Pix[o+0] = float32(cos*s) * a
+		in.Pix[o+1] = float32(sin*s) * a
+		in.Pix[o+2] = float32(s) * a
+		in.Pix[o+3] = float32(v) * a
+		in.Pix[o+4] = a
+	}
+
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+
+	result := newFloatImage(out.Rect, 4)
+	for i, o := 0, 0; i < len(out.Pix); i, o = i+5, o+4 {
+		a := out.Pix[i+4]
+		if a <= 0 {
+			continue
+		}
+		if a > 0xffff {
+			a = 0xffff
+		}
+		h := math.Atan2(float64(out.Pix[i+1]), float64(out.Pix[i+0])) * 180 / math.Pi
+		r, g, bl := hsvToRGB(h, float64(out.Pix[i+2]/a), float64(out.Pix[i+3]/a))
+		result.Pix[o+0] = float32(r) * a
+		result.Pix[o+1] = float32(g) * a
+		result.Pix[o+2] = float32(bl) * a
+		result.Pix[o+3] = a
+	}
+	return result.RGBA64()
+}
+
+// rgbToHSV converts a color with components in [0,1] to hue in degrees,
+// saturation and value.
+func rgbToHSV(r, g, b float64) (h, s, v float64) {
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	v = max
+	d := max - min
+	if max <= 0 || d <= 0 {
+		return 0, 0, v
+	}
+	s = d / max
+	switch max {
+	case r:
+		h = (g - b) / d
+	case g:
+		h = 2 + (b-r)/d
+	default:
+		h = 4 + (r-g)/d
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
+// hsvToRGB converts hue in degrees, saturation and value to a color with
+// components in [0,1]. Saturation and value are clamped to [0,1].
+func hsvToRGB(h, s, v float64) (r, g, b float64) {
+	s = math.Max(0, math.Min(s, 1))
+	v = math.Max(0, math.Min(v, 1))
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 60
+	i := math.Floor(h)
+	f := h - i
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+	switch int(i) {
+	case 0:
+		return v, t, p
+	case 1:
+		return q, v, p
+	case 2:
+		return p, v, t
+	case 3:
+		return p, q, v
+	case 4:
+		return t, p, v
+	}
+	return v, p, q
+}
+
+// ResizeLumaGamma scales the luminance of img, combined from red, green and
+// blue with the weights coeffs in linear light decoded with gamma, to a 16-bit
+// grayscale image. A gamma that is not a positive finite number is replaced
+// by 1.
+func ResizeLumaGamma(width, height uint, coeffs [3]float32, gamma float64, img image.Image, interp InterpolationFunction) *image.Gray16 {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return image.NewGray16(image.Rect(0, 0, 0, 0))
+	}
+
+	if !(gamma > 0) || math.IsInf(gamma, 1) {
+		gamma = 1
+	}
+
+	rgba := newFloatImageFromImage(img)
+	in := newFloatImage(rgba.Rect, 1)
+	for i := range in.Pix {
+		in.Pix[i] = coeffs[0]*float32(math.Pow(float64(rgba.Pix[4*i+0])/0xffff, gamma)) +
+			coeffs[1]*float32(math.Pow(float64(rgba.Pix[4*i+1])/0xffff, gamma)) +
+			coeffs[2]*float32(math.Pow(float64(rgba.Pix[4*i+2])/0xffff, gamma))
+	}
+
+	out := in
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		out = resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+	}
+
+	result := image.NewGray16(image.Rect(0, 0, int(width), int(height)))
+	for i, v := range out.Pix {
+		var value uint16
+		if v > 0 {
+			value = floatToUint16(float32(math.Pow(float64(v), 1/gamma)*0xffff) + 0.5)
+		}
+		result.Pix[2*i+0] = uint8(value >> 8)
+		result.Pix[2*i+1] = uint8(value)
+	}
+	return result
+}
+
+// ResizeChromaKey makes the pixels of img within tolerance of key transparent
+// and scales the result with premultiplied alpha.
+func ResizeChromaKey(width, height uint, key color.Color, tolerance float64, img image.Image, interp InterpolationFunction) *image.NRGBA {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return image.NewNRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	kc := color.NRGBA64Model.Convert(key).(color.NRGBA64)
+	in := newFloatImageFromImage(img)
+	for i := 0; i < len(in.Pix); i += 4 {
+		a := in.Pix[i+3]
+		if a == 0 {
+			continue
+		}
+		dr := float64(in.Pix[i+0]/a) - float64(kc.R)/0xffff
+		dg := float64(in.Pix[i+1]/a) - float64(kc.G)/0xffff
+		db := float64(in.Pix[i+2]/a) - float64(kc.B)/0xffff
+		if math.Sqrt(dr*dr+dg*dg+db*db) <= tolerance {
+			in.Pix[i+0], in.Pix[i+1], in.Pix[i+2], in.Pix[i+3] = 0, 0, 0, 0
+		}
+	}
+
+	out := in
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		out = resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+	}
+	return out.nrgba()
+}
+
+// ResizeHardAlpha scales an image like ResizeChromaKey without a key and makes
+// pixels with an alpha of at least threshold opaque, all others transparent.
+func ResizeHardAlpha(width, height uint, threshold uint8, img image.Image, interp InterpolationFunction) *image.NRGBA {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return image.NewNRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	out := newFloatImageFromImage(img)
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		out = resizeFloat(out, int(width), int(height), scaleX, scaleY, interp)
+	}
+
+	result := out.nrgba()
+	for i := 0; i < len(result.Pix); i += 4 {
+		if result.Pix[i+3] >= threshold && result.Pix[i+3] > 0 {
+			result.Pix[i+3] = 0xff
+		} else {
+			result.Pix[i+0], result.Pix[i+1], result.Pix[i+2], result.Pix[i+3] = 0, 0, 0, 0
+		}
+	}
+	return result
+}
+
+// ResizeAlpha scales an image with premultiplied colors and returns a new
+// *image.RGBA64, or an *image.NRGBA64 if premultiplied is false.
+func ResizeAlpha(width, height uint, img image.Image, interp InterpolationFunction, premultiplied bool) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	// Premultiplied colors and alpha, followed by the straight colors.
+	out := newFloatImage(image.Rect(0, 0, b.Dx(), b.Dy()), 7)
+	parallelRows(b.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			i := out.PixOffset(0, y)
+			for x := 0; x < b.Dx(); x++ {
+				c := img.At(x+b.Min.X, y+b.Min.Y)
+				r, g, bl, a := c.RGBA()
+				n := straightColor(c)
+				out.Pix[i+0], out.Pix[i+1], out.Pix[i+2], out.Pix[i+3] = float32(r), float32(g), float32(bl), float32(a)
+				out.Pix[i+4], out.Pix[i+5], out.Pix[i+6] = float32(n.R), float32(n.G), float32(n.B)
+				i += 7
+			}
+		}
+	})
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		out = resizeFloat(out, int(width), int(height), scaleX, scaleY, interp)
+	}
+
+	r := image.Rect(0, 0, int(width), int(height))
+	if premultiplied {
+		result := image.NewRGBA64(r)
+		for i, o := 0, 0; i < len(out.Pix); i, o = i+7, o+8 {
+			a := clampFloatUint16(out.Pix[i+3])
+			for c := 0; c < 3; c++ {
+				v := clampFloatUint16(out.Pix[i+c])
+				if v > a {
+					v = a
+				}
+				result.Pix[o+2*c+0], result.Pix[o+2*c+1] = uint8(v>>8), uint8(v)
+			}
+			result.Pix[o+6], result.Pix[o+7] = uint8(a>>8), uint8(a)
+		}
+		return result
+	}
+
+	result := image.NewNRGBA64(r)
+	for i, o := 0, 0; i < len(out.Pix); i, o = i+7, o+8 {
+		a := clampFloatUint16(out.Pix[i+3])
+		for c := 0; c < 3; c++ {
+			var v uint16
+			if a == 0 {
+				v = clampFloatUint16(out.Pix[i+4+c])
+			} else {
+				v = clampFloatUint16(out.Pix[i+c] / float32(a) * 0xffff)
+			}
+			result.Pix[o+2*c+0], result.Pix[o+2*c+1] = uint8(v>>8), uint8(v)
+		}
+		result.Pix[o+6], result.Pix[o+7] = uint8(a>>8), uint8(a)
+	}
+	return result
+}
+
+// straightColor returns c with straight alpha. Unlike the conversion by
+// color.NRGBA64Model, it keeps the color of transparent straight colors.
+func straightColor(c color.Color) color.NRGBA64 {
+	switch c := c.(type) {
+	case color.NRGBA:
+		return color.NRGBA64{uint16(c.R) * 0x101, uint16(c.G) * 0x101, uint16(c.B) * 0x101, uint16(c.A) * 0x101}
+	case color.NRGBA64:
+		return c
+	}
+	return color.NRGBA64Model.Convert(c).(color.NRGBA64)
+}
+
+// nrgba converts a floatImage with four channels of 16-bit premultiplied
+// colors to an *image.NRGBA.
+func (p *floatImage) nrgba() *image.NRGBA {
+	result := image.NewNRGBA(p.Rect)
+	for i := 0; i < len(p.Pix); i += 4 {
+		a := p.Pix[i+3]
+		if a <= 0 {
+			continue
+		}
+		if a > 0xffff {
+			a = 0xffff
+		}
+		for c := 0; c < 3; c++ {
+			if v := p.Pix[i+c]; v > 0 {
+				result.Pix[i+c] = floatToUint8(v/a*0xff + 0.5)
+			}
+		}
+		result.Pix[i+3] = uint8(clampFloatUint16(a) >> 8)
+	}
+	return result
+}
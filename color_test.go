@@ -0,0 +1,457 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func Test_ResizeColorMatrixIdentity(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 30, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			img.SetRGBA64(x, y, color.RGBA64{uint16(x * 2000), uint16(y * 3000), 0x8000, 0xffff})
+		}
+	}
+	identity := [3][4]float32{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+	}
+
+	got := ResizeColorMatrix(12, 9, identity, img, Bilinear)
+	want := Resize(12, 9, img, Bilinear)
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 12; x++ {
+			r0, g0, b0, a0 := got.At(x, y).RGBA()
+			r1, g1, b1, a1 := want.At(x, y).RGBA()
+			if absDiff(r0, r1) > 2 || absDiff(g0, g1) > 2 || absDiff(b0, b1) > 2 || a0 != a1 {
+				t.Fatalf("(%d,%d): want %v, got %v", x, y, want.At(x, y), got.At(x, y))
+			}
+		}
+	}
+}
+
+func Test_ResizeColorMatrixSepia(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 20))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	sepia := [3][4]float32{
+		{0.393, 0.769, 0.189, 0},
+		{0.349, 0.686, 0.168, 0},
+		{0.272, 0.534, 0.131, 0},
+	}
+
+	out := ResizeColorMatrix(10, 10, sepia, img, Lanczos3)
+	c := color.RGBA64Model.Convert(out.At(5, 5)).(color.RGBA64)
+	gray := float64(0x8080) / 0xffff
+	want := [3]float64{1.351 * gray, 1.203 * gray, 0.937 * gray}
+	got := [3]float64{float64(c.R) / 0xffff, float64(c.G) / 0xffff, float64(c.B) / 0xffff}
+	for i := range want {
+		if want[i] > 1 {
+			want[i] = 1
+		}
+		if d := got[i] - want[i]; d < -0.001 || d > 0.001 {
+			t.Errorf("channel %d: want %.4f, got %.4f", i, want[i], got[i])
+		}
+	}
+	if !(c.R >= c.G && c.G > c.B) {
+		t.Errorf("no sepia tint: %v", c)
+	}
+}
+
+func Test_ResizeColorMatrixUnchangedSize(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 30, 20))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	for i := 6; i < len(img.Pix); i += 8 {
+		img.Pix[i], img.Pix[i+1] = 0xff, 0xff
+	}
+	identity := [3][4]float32{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+	}
+
+	out := ResizeColorMatrix(30, 20, identity, img, MitchellNetravali)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			r0, g0, b0, a0 := out.At(x, y).RGBA()
+			r1, g1, b1, a1 := img.At(x, y).RGBA()
+			if absDiff(r0, r1) > 2 || absDiff(g0, g1) > 2 || absDiff(b0, b1) > 2 || a0 != a1 {
+				t.Fatalf("(%d,%d): want %v, got %v", x, y, img.At(x, y), out.At(x, y))
+			}
+		}
+	}
+}
+
+func Test_ResizeColorMatrixTransparent(t *testing.T) {
+	// A white and a black dot on a transparent background, whose ringing
+	// gives pixels without coverage but with color.
+	img := image.NewRGBA64(image.Rect(0, 0, 9, 9))
+	img.SetRGBA64(4, 4, color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff})
+	img.SetRGBA64(5, 4, color.RGBA64{0, 0, 0, 0xffff})
+	lift := [3][4]float32{
+		{1, 0, 0, 0.5},
+		{0, 1, 0, 0.5},
+		{0, 0, 1, 0.5},
+	}
+
+	out := ResizeColorMatrix(40, 40, lift, img, Lanczos3).(*image.RGBA64)
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			if c := out.RGBA64At(x, y); c.R > c.A || c.G > c.A || c.B > c.A {
+				t.Fatalf("(%d,%d): color %v exceeds alpha", x, y, c)
+			}
+		}
+	}
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// softDisk returns a disk of color c with a soft edge on a transparent
+// background of color bg.
+func softDisk(size int, c, bg color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	r := float64(size) / 3
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)-float64(size)/2+0.5, float64(y)-float64(size)/2+0.5
+			d := r - math.Sqrt(dx*dx+dy*dy)
+			switch {
+			case d >= 2:
+				img.SetNRGBA(x, y, c)
+			case d > 0:
+				img.SetNRGBA(x, y, color.NRGBA{c.R, c.G, c.B, uint8(d / 2 * 0xff)})
+			default:
+				img.SetNRGBA(x, y, bg)
+			}
+		}
+	}
+	return img
+}
+
+func Test_ResizeLogo(t *testing.T) {
+	var testData = []struct {
+		logo, background color.NRGBA
+	}{
+		{color.NRGBA{0, 0, 0, 0xff}, color.NRGBA{0xff, 0xff, 0xff, 0}},
+		{color.NRGBA{0xff, 0xff, 0xff, 0xff}, color.NRGBA{0, 0, 0, 0}},
+		{color.NRGBA{0xff, 0x20, 0x00, 0xff}, color.NRGBA{0x00, 0xff, 0xff, 0}},
+	}
+	for _, test := range testData {
+		img := softDisk(64, test.logo, test.background)
+		out := ResizeLogo(21, 21, img)
+		if out.Bounds() != image.Rect(0, 0, 21, 21) {
+			t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 21, 21), out.Bounds())
+		}
+		var soft int
+		for y := 0; y < 21; y++ {
+			for x := 0; x < 21; x++ {
+				c := out.NRGBAAt(x, y)
+				if c.A == 0 {
+					continue
+				}
+				if c.A < 0xff {
+					soft++
+				}
+				if absDiff(uint32(c.R), uint32(test.logo.R)) > 1 ||
+					absDiff(uint32(c.G), uint32(test.logo.G)) > 1 ||
+					absDiff(uint32(c.B), uint32(test.logo.B)) > 1 {
+					t.Fatalf("logo %v: color changed at (%d,%d): %v", test.logo, x, y, c)
+				}
+			}
+		}
+		if soft == 0 {
+			t.Errorf("logo %v: no soft edge left", test.logo)
+		}
+	}
+
+	img := softDisk(64, color.NRGBA{0xff, 0x20, 0x00, 0xff}, color.NRGBA{0x00, 0xff, 0xff, 0})
+	if out := ResizeLogo(64, 64, img); !bytes.Equal(out.Pix, img.Pix) {
+		t.Error("unchanged size: the logo was filtered")
+	}
+
+	if m := ResizeLogo(5, 5, image.NewRGBA(image.Rect(0, 0, 0, 0))); !m.Bounds().Empty() {
+		t.Errorf("empty source: want an empty result, got bounds %v", m.Bounds())
+	}
+}
+
+func Test_ResizeHSV(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.SetRGBA(0, 0, color.RGBA{0xff, 0, 0, 0xff})
+	img.SetRGBA(1, 0, color.RGBA{0, 0, 0xff, 0xff})
+
+	c := color.RGBA64Model.Convert(ResizeHSV(1, 1, img, Bilinear).At(0, 0)).(color.RGBA64)
+	if c.R < 0xf000 || c.B < 0xf000 || c.G > 0x1000 || c.A != 0xffff {
+		t.Errorf("want saturated magenta, got %v", c)
+	}
+
+	rgb := color.RGBA64Model.Convert(Resize(1, 1, img, Bilinear).At(0, 0)).(color.RGBA64)
+	if rgb.R >= c.R || rgb.B >= c.B {
+		t.Errorf("HSV blend %v is not brighter than RGB blend %v", c, rgb)
+	}
+}
+
+func Test_HSVRoundTrip(t *testing.T) {
+	for _, c := range [][3]float64{{1, 0, 0}, {0.2, 0.4, 0.6}, {0.5, 0.5, 0.5}, {0, 1, 0.3}, {0.9, 0.1, 0.7}} {
+		h, s, v := rgbToHSV(c[0], c[1], c[2])
+		r, g, b := hsvToRGB(h, s, v)
+		if math.Abs(r-c[0]) > 1e-9 || math.Abs(g-c[1]) > 1e-9 || math.Abs(b-c[2]) > 1e-9 {
+			t.Errorf("%v: got %v %v %v", c, r, g, b)
+		}
+	}
+}
+
+func Test_ResizePremultRGBAOver(t *testing.T) {
+	// Opaque red disk with a soft edge on transparent blue.
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			d := math.Hypot(float64(x)-31.5, float64(y)-31.5)
+			a := math.Max(0, math.Min(1, 24-d))
+			if a > 0 {
+				img.SetNRGBA(x, y, color.NRGBA{0xff, 0, 0, uint8(a*0xff + 0.5)})
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{0, 0, 0xff, 0})
+			}
+		}
+	}
+
+	small := ResizePremultRGBA(16, 16, img, Lanczos3)
+	dst := image.NewRGBA(small.Rect)
+	draw.Draw(dst, dst.Rect, image.NewUniform(color.RGBA{0, 0xff, 0, 0xff}), image.ZP, draw.Src)
+	draw.Draw(dst, dst.Rect, small, image.ZP, draw.Over)
+
+	edges := 0
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			a := small.RGBAAt(x, y).A
+			c := dst.RGBAAt(x, y)
+			if a > 0 && a < 0xff {
+				edges++
+			}
+			if c.B > 1 {
+				t.Errorf("(%d,%d): transparent color bleeds into %v", x, y, c)
+			}
+			if int(c.R)+int(c.G) < 0xfe || int(c.R)+int(c.G) > 0x100 {
+				t.Errorf("(%d,%d): %v is not a blend of red and green", x, y, c)
+			}
+			if absDiff(uint32(c.R), uint32(a)) > 1 {
+				t.Errorf("(%d,%d): want red %d, got %d", x, y, a, c.R)
+			}
+		}
+	}
+	if edges == 0 {
+		t.Error("no soft edge pixels")
+	}
+}
+
+func Test_ResizePremultLinear(t *testing.T) {
+	// White with an alpha ramp, premultiplied.
+	img := image.NewRGBA(image.Rect(0, 0, 64, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 64; x++ {
+			a := uint8(x * 4)
+			img.SetRGBA(x, y, color.RGBA{a, a, a, a})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m := ResizePremultLinear(24, 3, img, interp)
+		if m.Rect != image.Rect(0, 0, 24, 3) {
+			t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 24, 3), m.Rect)
+		}
+		for y := 0; y < 3; y++ {
+			for x := 0; x < 24; x++ {
+				// White stays white after unpremultiplying.
+				c := m.RGBAAt(x, y)
+				if absDiff(uint32(c.R), uint32(c.A)) > 1 || c.G != c.R || c.B != c.R {
+					t.Errorf("interp %v (%d,%d): %v is not premultiplied white", interp, x, y, c)
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeLumaGamma(t *testing.T) {
+	rec709 := [3]float32{0.2126, 0.7152, 0.0722}
+
+	white := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := range white.Pix {
+		white.Pix[i] = 0xff
+	}
+	m := ResizeLumaGamma(7, 7, rec709, 2.2, white, Lanczos3)
+	for i := 0; i < len(m.Pix); i += 2 {
+		if v := uint16(m.Pix[i])<<8 | uint16(m.Pix[i+1]); v < 0xfffe {
+			t.Fatalf("white turned into %d", v)
+		}
+	}
+
+	// Black and white columns average to half the linear light.
+	stripes := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	stripes.SetRGBA(1, 0, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	stripes.SetRGBA(1, 1, color.RGBA{0xff, 0xff, 0xff, 0xff})
+	m = ResizeLumaGamma(1, 1, rec709, 2.2, stripes, Bilinear)
+	want := math.Pow(0.5, 1/2.2) * 0xffff
+	if got := float64(m.Gray16At(0, 0).Y); math.Abs(got-want) > 2 {
+		t.Errorf("want %.0f, got %.0f", want, got)
+	}
+
+	// Invalid gammas are replaced by 1.
+	linear := ResizeLumaGamma(1, 1, rec709, 1, stripes, Bilinear)
+	for _, gamma := range []float64{0, -2, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if m := ResizeLumaGamma(1, 1, rec709, gamma, stripes, Bilinear); !bytes.Equal(m.Pix, linear.Pix) {
+			t.Errorf("gamma %v: want %v, got %v", gamma, linear.Pix, m.Pix)
+		}
+	}
+
+	if m := ResizeLumaGamma(5, 5, rec709, 2.2, image.NewRGBA(image.Rect(0, 0, 0, 0)), Bilinear); !m.Bounds().Empty() {
+		t.Errorf("empty source: want an empty result, got bounds %v", m.Bounds())
+	}
+}
+
+func Test_ResizeChromaKey(t *testing.T) {
+	// A blue disk on a slightly noisy red background.
+	img := image.NewRGBA(image.Rect(0, 0, 60, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			if math.Hypot(float64(x)-29.5, float64(y)-29.5) < 20 {
+				img.SetRGBA(x, y, color.RGBA{0, 0x20, 0xe0, 0xff})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0xff - uint8(x%5), uint8(y % 3), 0, 0xff})
+			}
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m := ResizeChromaKey(17, 17, color.RGBA{0xff, 0, 0, 0xff}, 0.05, img, interp)
+		if a := m.NRGBAAt(0, 0).A; a != 0 {
+			t.Errorf("%v: background is not transparent: %d", interp, a)
+		}
+		if c := m.NRGBAAt(8, 8); c.A != 0xff || c.B < 0xd0 {
+			t.Errorf("%v: center is not opaque blue: %v", interp, c)
+		}
+		edges := 0
+		for y := 0; y < 17; y++ {
+			for x := 0; x < 17; x++ {
+				c := m.NRGBAAt(x, y)
+				if c.A > 0 && c.A < 0xff {
+					edges++
+				}
+				if c.A > 0 && c.R > 2 {
+					t.Errorf("%v: (%d,%d): red fringe %v", interp, x, y, c)
+				}
+			}
+		}
+		if edges == 0 {
+			t.Errorf("%v: no soft edges", interp)
+		}
+	}
+
+	if m := ResizeChromaKey(5, 5, color.RGBA{0xff, 0, 0, 0xff}, 0.05, image.NewRGBA(image.Rect(0, 0, 0, 0)), Bilinear); !m.Bounds().Empty() {
+		t.Errorf("empty source: want an empty result, got bounds %v", m.Bounds())
+	}
+}
+
+func Test_ResizeHardAlpha(t *testing.T) {
+	// Red disk with a soft edge.
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			d := math.Hypot(float64(x)-31.5, float64(y)-31.5)
+			a := math.Max(0, math.Min(1, (24-d)/4))
+			img.SetNRGBA(x, y, color.NRGBA{0xff, 0, 0, uint8(a*0xff + 0.5)})
+		}
+	}
+
+	m := ResizeHardAlpha(20, 20, 128, img, Lanczos3)
+	opaque := 0
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			c := m.NRGBAAt(x, y)
+			switch c.A {
+			case 0:
+				if c != (color.NRGBA{}) {
+					t.Errorf("(%d,%d): transparent pixel has color %v", x, y, c)
+				}
+			case 0xff:
+				opaque++
+				if c.R < 0xfe {
+					t.Errorf("(%d,%d): want red, got %v", x, y, c)
+				}
+			default:
+				t.Errorf("(%d,%d): soft alpha %d", x, y, c.A)
+			}
+		}
+	}
+	if opaque == 0 || opaque == 20*20 {
+		t.Errorf("want a disk, got %d opaque pixels", opaque)
+	}
+
+	if m := ResizeHardAlpha(5, 5, 128, image.NewRGBA(image.Rect(0, 0, 0, 0)), Lanczos3); !m.Bounds().Empty() {
+		t.Errorf("empty source: want an empty result, got bounds %v", m.Bounds())
+	}
+}
+
+func Test_ResizeAlpha(t *testing.T) {
+	// An opaque red bar with soft edges on transparent green.
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 4))
+	for x := 0; x < 40; x++ {
+		c := color.NRGBA{0, 0xff, 0, 0}
+		switch {
+		case x >= 16 && x < 24:
+			c = color.NRGBA{0xff, 0, 0, 0xff}
+		case x >= 12 && x < 28:
+			c = color.NRGBA{0xff, 0, 0, 0x80}
+		}
+		for y := 0; y < 4; y++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	premult := ResizeAlpha(20, 2, img, Lanczos3, true).(*image.RGBA64)
+	straight := ResizeAlpha(20, 2, img, Lanczos3, false).(*image.NRGBA64)
+	var transparent, soft int
+	for x := 0; x < 20; x++ {
+		p, s := premult.RGBA64At(x, 1), straight.NRGBA64At(x, 1)
+		if p.A != s.A {
+			t.Fatalf("x=%d: alpha differs: %d, %d", x, p.A, s.A)
+		}
+		if p.A == 0 {
+			transparent++
+			if p != (color.RGBA64{}) {
+				t.Errorf("x=%d: premultiplied transparent pixel is %v", x, p)
+			}
+			if s.G < 0x8000 {
+				t.Errorf("x=%d: straight transparent pixel lost its color: %v", x, s)
+			}
+			continue
+		}
+		if p.A < 0xffff {
+			soft++
+		}
+		// Premultiplying the straight result gives the premultiplied one.
+		if r, g, b, a := s.RGBA(); absDiff(r, uint32(p.R)) > 1 || absDiff(g, uint32(p.G)) > 1 ||
+			absDiff(b, uint32(p.B)) > 1 || a != uint32(p.A) {
+			t.Errorf("x=%d: premultiplied %v, straight %v", x, p, s)
+		}
+		// Transparent green does not bleed into the edge.
+		if p.G > p.R/64 {
+			t.Errorf("x=%d: green bleeds into %v", x, p)
+		}
+	}
+	if transparent == 0 || soft == 0 {
+		t.Errorf("want transparent and soft pixels, got %d and %d", transparent, soft)
+	}
+}
@@ -16,7 +16,10 @@ THIS SOFTWARE.
 
 package resize
 
-import "image"
+import (
+	"image"
+	"image/color"
+)
 
 // Keep value in [0,255] range.
 func clampUint8(in int32) uint8 {
@@ -32,6 +35,18 @@ func clampUint8(in int32) uint8 {
 	return 0
 }
 
+// normalize8 rounds a sum of pixels weighted by createWeights8 to the
+// pixel value. The weights of every output pixel sum to 1<<weightBits8.
+func normalize8(in int32) int32 {
+	return (in + 1<<(weightBits8-1)) >> weightBits8
+}
+
+// normalize16 divides a sum of pixels weighted by createWeights16 by the
+// sum of the weights, rounded to the nearest integer.
+func normalize16(in, sum int64) int64 {
+	return (in + sum/2) / sum
+}
+
 // Keep value in [0,65535] range.
 func clampUint16(in int64) uint16 {
 	if uint64(in) < 65536 {
@@ -76,22 +91,65 @@ func resizeGeneric(in image.Image, out *image.RGBA64, scale float64, coeffs []in
 
 			offset := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
 
-			value := clampUint16(rgba[0] / sum)
+			value := clampUint16(normalize16(rgba[0], sum))
 			out.Pix[offset+0] = uint8(value >> 8)
 			out.Pix[offset+1] = uint8(value)
-			value = clampUint16(rgba[1] / sum)
+			value = clampUint16(normalize16(rgba[1], sum))
 			out.Pix[offset+2] = uint8(value >> 8)
 			out.Pix[offset+3] = uint8(value)
-			value = clampUint16(rgba[2] / sum)
+			value = clampUint16(normalize16(rgba[2], sum))
 			out.Pix[offset+4] = uint8(value >> 8)
 			out.Pix[offset+5] = uint8(value)
-			value = clampUint16(rgba[3] / sum)
+			value = clampUint16(normalize16(rgba[3], sum))
 			out.Pix[offset+6] = uint8(value >> 8)
 			out.Pix[offset+7] = uint8(value)
 		}
 	}
 }
 
+// resizeGenericRGBA is like resizeGeneric, but rounds the result to 8 bits.
+func resizeGenericRGBA(in image.Image, out *image.RGBA, scale float64, coeffs []int32, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgba [4]int64
+			var sum int64
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case xi < 0:
+						xi = 0
+					case xi >= maxX:
+						xi = maxX
+					}
+
+					r, g, b, a := in.At(xi+in.Bounds().Min.X, x+in.Bounds().Min.Y).RGBA()
+
+					rgba[0] += int64(coeff) * int64(r)
+					rgba[1] += int64(coeff) * int64(g)
+					rgba[2] += int64(coeff) * int64(b)
+					rgba[3] += int64(coeff) * int64(a)
+					sum += int64(coeff)
+				}
+			}
+
+			offset := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
+
+			// Rounding is monotonic, so colors never exceed alpha.
+			for c := 0; c < 4; c++ {
+				value := uint32(clampUint16(normalize16(rgba[c], sum)))
+				out.Pix[offset+c] = uint8((value + 0x80) * 0xff01 >> 24)
+			}
+		}
+	}
+}
+
 func resizeRGBA(in *image.RGBA, out *image.RGBA, scale float64, coeffs []int16, offset []int, filterLength int) {
 	newBounds := out.Bounds()
 	maxX := in.Bounds().Dx() - 1
@@ -100,7 +158,6 @@ func resizeRGBA(in *image.RGBA, out *image.RGBA, scale float64, coeffs []int16,
 		row := in.Pix[x*in.Stride:]
 		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
 			var rgba [4]int32
-			var sum int32
 			start := offset[y]
 			ci := y * filterLength
 			for i := 0; i < filterLength; i++ {
@@ -120,16 +177,15 @@ func resizeRGBA(in *image.RGBA, out *image.RGBA, scale float64, coeffs []int16,
 					rgba[1] += int32(coeff) * int32(row[xi+1])
 					rgba[2] += int32(coeff) * int32(row[xi+2])
 					rgba[3] += int32(coeff) * int32(row[xi+3])
-					sum += int32(coeff)
 				}
 			}
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
 
-			out.Pix[xo+0] = clampUint8(rgba[0] / sum)
-			out.Pix[xo+1] = clampUint8(rgba[1] / sum)
-			out.Pix[xo+2] = clampUint8(rgba[2] / sum)
-			out.Pix[xo+3] = clampUint8(rgba[3] / sum)
+			out.Pix[xo+0] = clampUint8(normalize8(rgba[0]))
+			out.Pix[xo+1] = clampUint8(normalize8(rgba[1]))
+			out.Pix[xo+2] = clampUint8(normalize8(rgba[2]))
+			out.Pix[xo+3] = clampUint8(normalize8(rgba[3]))
 		}
 	}
 }
@@ -142,7 +198,6 @@ func resizeNRGBA(in *image.NRGBA, out *image.RGBA, scale float64, coeffs []int16
 		row := in.Pix[x*in.Stride:]
 		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
 			var rgba [4]int32
-			var sum int32
 			start := offset[y]
 			ci := y * filterLength
 			for i := 0; i < filterLength; i++ {
@@ -158,29 +213,71 @@ func resizeNRGBA(in *image.NRGBA, out *image.RGBA, scale float64, coeffs []int16
 						xi = 0
 					}
 
-					// Forward alpha-premultiplication
+					// Forward alpha-premultiplication, which is the
+					// identity for opaque pixels
 					a := int32(row[xi+3])
-					r := int32(row[xi+0]) * a
-					r /= 0xff
-					g := int32(row[xi+1]) * a
-					g /= 0xff
-					b := int32(row[xi+2]) * a
-					b /= 0xff
+					r := int32(row[xi+0])
+					g := int32(row[xi+1])
+					b := int32(row[xi+2])
+					if a != 0xff {
+						r = (r*a + 0x7f) / 0xff
+						g = (g*a + 0x7f) / 0xff
+						b = (b*a + 0x7f) / 0xff
+					}
 
 					rgba[0] += int32(coeff) * r
 					rgba[1] += int32(coeff) * g
 					rgba[2] += int32(coeff) * b
 					rgba[3] += int32(coeff) * a
-					sum += int32(coeff)
 				}
 			}
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
 
-			out.Pix[xo+0] = clampUint8(rgba[0] / sum)
-			out.Pix[xo+1] = clampUint8(rgba[1] / sum)
-			out.Pix[xo+2] = clampUint8(rgba[2] / sum)
-			out.Pix[xo+3] = clampUint8(rgba[3] / sum)
+			out.Pix[xo+0] = clampUint8(normalize8(rgba[0]))
+			out.Pix[xo+1] = clampUint8(normalize8(rgba[1]))
+			out.Pix[xo+2] = clampUint8(normalize8(rgba[2]))
+			out.Pix[xo+3] = clampUint8(normalize8(rgba[3]))
+		}
+	}
+}
+
+func resizeCMYK(in *image.CMYK, out *image.RGBA, scale float64, coeffs []int16, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgb [3]int32
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case uint(xi) < uint(maxX):
+						xi *= 4
+					case xi >= maxX:
+						xi = 4 * maxX
+					default:
+						xi = 0
+					}
+
+					r, g, b := color.CMYKToRGB(row[xi+0], row[xi+1], row[xi+2], row[xi+3])
+					rgb[0] += int32(coeff) * int32(r)
+					rgb[1] += int32(coeff) * int32(g)
+					rgb[2] += int32(coeff) * int32(b)
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
+
+			out.Pix[xo+0] = clampUint8(normalize8(rgb[0]))
+			out.Pix[xo+1] = clampUint8(normalize8(rgb[1]))
+			out.Pix[xo+2] = clampUint8(normalize8(rgb[2]))
+			out.Pix[xo+3] = 0xff
 		}
 	}
 }
@@ -219,22 +316,66 @@ func resizeRGBA64(in *image.RGBA64, out *image.RGBA64, scale float64, coeffs []i
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
 
-			value := clampUint16(rgba[0] / sum)
+			value := clampUint16(normalize16(rgba[0], sum))
 			out.Pix[xo+0] = uint8(value >> 8)
 			out.Pix[xo+1] = uint8(value)
-			value = clampUint16(rgba[1] / sum)
+			value = clampUint16(normalize16(rgba[1], sum))
 			out.Pix[xo+2] = uint8(value >> 8)
 			out.Pix[xo+3] = uint8(value)
-			value = clampUint16(rgba[2] / sum)
+			value = clampUint16(normalize16(rgba[2], sum))
 			out.Pix[xo+4] = uint8(value >> 8)
 			out.Pix[xo+5] = uint8(value)
-			value = clampUint16(rgba[3] / sum)
+			value = clampUint16(normalize16(rgba[3], sum))
 			out.Pix[xo+6] = uint8(value >> 8)
 			out.Pix[xo+7] = uint8(value)
 		}
 	}
 }
 
+// resizeRGBA64ToRGBA is like resizeRGBA64, but rounds the result to 8 bits.
+func resizeRGBA64ToRGBA(in *image.RGBA64, out *image.RGBA, scale float64, coeffs []int32, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgba [4]int64
+			var sum int64
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case uint(xi) < uint(maxX):
+						xi *= 8
+					case xi >= maxX:
+						xi = 8 * maxX
+					default:
+						xi = 0
+					}
+
+					rgba[0] += int64(coeff) * (int64(row[xi+0])<<8 | int64(row[xi+1]))
+					rgba[1] += int64(coeff) * (int64(row[xi+2])<<8 | int64(row[xi+3]))
+					rgba[2] += int64(coeff) * (int64(row[xi+4])<<8 | int64(row[xi+5]))
+					rgba[3] += int64(coeff) * (int64(row[xi+6])<<8 | int64(row[xi+7]))
+					sum += int64(coeff)
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
+
+			// Rounding is monotonic, so colors never exceed alpha.
+			for c := 0; c < 4; c++ {
+				value := uint32(clampUint16(normalize16(rgba[c], sum)))
+				out.Pix[xo+c] = uint8((value + 0x80) * 0xff01 >> 24)
+			}
+		}
+	}
+}
+
 func resizeNRGBA64(in *image.NRGBA64, out *image.RGBA64, scale float64, coeffs []int32, offset []int, filterLength int) {
 	newBounds := out.Bounds()
 	maxX := in.Bounds().Dx() - 1
@@ -259,14 +400,17 @@ func resizeNRGBA64(in *image.NRGBA64, out *image.RGBA64, scale float64, coeffs [
 						xi = 0
 					}
 
-					// Forward alpha-premultiplication
+					// Forward alpha-premultiplication, which is the
+					// identity for opaque pixels
 					a := int64(uint16(row[xi+6])<<8 | uint16(row[xi+7]))
-					r := int64(uint16(row[xi+0])<<8|uint16(row[xi+1])) * a
-					r /= 0xffff
-					g := int64(uint16(row[xi+2])<<8|uint16(row[xi+3])) * a
-					g /= 0xffff
-					b := int64(uint16(row[xi+4])<<8|uint16(row[xi+5])) * a
-					b /= 0xffff
+					r := int64(uint16(row[xi+0])<<8 | uint16(row[xi+1]))
+					g := int64(uint16(row[xi+2])<<8 | uint16(row[xi+3]))
+					b := int64(uint16(row[xi+4])<<8 | uint16(row[xi+5]))
+					if a != 0xffff {
+						r = (r*a + 0x7fff) / 0xffff
+						g = (g*a + 0x7fff) / 0xffff
+						b = (b*a + 0x7fff) / 0xffff
+					}
 
 					rgba[0] += int64(coeff) * r
 					rgba[1] += int64(coeff) * g
@@ -278,16 +422,139 @@ func resizeNRGBA64(in *image.NRGBA64, out *image.RGBA64, scale float64, coeffs [
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
 
-			value := clampUint16(rgba[0] / sum)
+			value := clampUint16(normalize16(rgba[0], sum))
 			out.Pix[xo+0] = uint8(value >> 8)
 			out.Pix[xo+1] = uint8(value)
-			value = clampUint16(rgba[1] / sum)
+			value = clampUint16(normalize16(rgba[1], sum))
 			out.Pix[xo+2] = uint8(value >> 8)
 			out.Pix[xo+3] = uint8(value)
-			value = clampUint16(rgba[2] / sum)
+			value = clampUint16(normalize16(rgba[2], sum))
 			out.Pix[xo+4] = uint8(value >> 8)
 			out.Pix[xo+5] = uint8(value)
-			value = clampUint16(rgba[3] / sum)
+			value = clampUint16(normalize16(rgba[3], sum))
+			out.Pix[xo+6] = uint8(value >> 8)
+			out.Pix[xo+7] = uint8(value)
+		}
+	}
+}
+
+// resizeNYCbCrA is resizeGeneric for YCbCr images with an alpha plane,
+// which reads the planes directly instead of calling At.
+func resizeNYCbCrA(in *image.NYCbCrA, out *image.RGBA64, scale float64, coeffs []int32, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		py := in.Rect.Min.Y + x
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgba [4]int64
+			var sum int64
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case xi < 0:
+						xi = 0
+					case xi >= maxX:
+						xi = maxX
+					}
+
+					px := in.Rect.Min.X + xi
+					co := in.COffset(px, py)
+					c := color.NYCbCrA{
+						YCbCr: color.YCbCr{Y: in.Y[in.YOffset(px, py)], Cb: in.Cb[co], Cr: in.Cr[co]},
+						A:     in.A[in.AOffset(px, py)],
+					}
+					r, g, b, a := c.RGBA()
+
+					rgba[0] += int64(coeff) * int64(r)
+					rgba[1] += int64(coeff) * int64(g)
+					rgba[2] += int64(coeff) * int64(b)
+					rgba[3] += int64(coeff) * int64(a)
+					sum += int64(coeff)
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
+
+			value := clampUint16(normalize16(rgba[0], sum))
+			out.Pix[xo+0] = uint8(value >> 8)
+			out.Pix[xo+1] = uint8(value)
+			value = clampUint16(normalize16(rgba[1], sum))
+			out.Pix[xo+2] = uint8(value >> 8)
+			out.Pix[xo+3] = uint8(value)
+			value = clampUint16(normalize16(rgba[2], sum))
+			out.Pix[xo+4] = uint8(value >> 8)
+			out.Pix[xo+5] = uint8(value)
+			value = clampUint16(normalize16(rgba[3], sum))
+			out.Pix[xo+6] = uint8(value >> 8)
+			out.Pix[xo+7] = uint8(value)
+		}
+	}
+}
+
+// paletteRGBA64 returns the premultiplied 16-bit colors of the entries of p
+// as returned by RGBA. Indices beyond the palette are transparent black.
+func paletteRGBA64(p color.Palette) *[256][4]int64 {
+	var colors [256][4]int64
+	for i, c := range p {
+		if i == len(colors) {
+			break
+		}
+		r, g, b, a := c.RGBA()
+		colors[i] = [4]int64{int64(r), int64(g), int64(b), int64(a)}
+	}
+	return &colors
+}
+
+// resizePaletted is resizeGeneric for paletted images, which looks up the
+// colors of the palette indices in colors instead of calling At.
+func resizePaletted(in *image.Paletted, out *image.RGBA64, scale float64, colors *[256][4]int64, coeffs []int32, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgba [4]int64
+			var sum int64
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case xi < 0:
+						xi = 0
+					case xi >= maxX:
+						xi = maxX
+					}
+
+					c := &colors[row[xi]]
+					rgba[0] += int64(coeff) * c[0]
+					rgba[1] += int64(coeff) * c[1]
+					rgba[2] += int64(coeff) * c[2]
+					rgba[3] += int64(coeff) * c[3]
+					sum += int64(coeff)
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
+
+			value := clampUint16(normalize16(rgba[0], sum))
+			out.Pix[xo+0] = uint8(value >> 8)
+			out.Pix[xo+1] = uint8(value)
+			value = clampUint16(normalize16(rgba[1], sum))
+			out.Pix[xo+2] = uint8(value >> 8)
+			out.Pix[xo+3] = uint8(value)
+			value = clampUint16(normalize16(rgba[2], sum))
+			out.Pix[xo+4] = uint8(value >> 8)
+			out.Pix[xo+5] = uint8(value)
+			value = clampUint16(normalize16(rgba[3], sum))
 			out.Pix[xo+6] = uint8(value >> 8)
 			out.Pix[xo+7] = uint8(value)
 		}
@@ -302,7 +569,6 @@ func resizeGray(in *image.Gray, out *image.Gray, scale float64, coeffs []int16,
 		row := in.Pix[(x-newBounds.Min.X)*in.Stride:]
 		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
 			var gray int32
-			var sum int32
 			start := offset[y]
 			ci := y * filterLength
 			for i := 0; i < filterLength; i++ {
@@ -316,12 +582,11 @@ func resizeGray(in *image.Gray, out *image.Gray, scale float64, coeffs []int16,
 						xi = maxX
 					}
 					gray += int32(coeff) * int32(row[xi])
-					sum += int32(coeff)
 				}
 			}
 
 			offset := (y-newBounds.Min.Y)*out.Stride + (x - newBounds.Min.X)
-			out.Pix[offset] = clampUint8(gray / sum)
+			out.Pix[offset] = clampUint8(normalize8(gray))
 		}
 	}
 }
@@ -355,7 +620,7 @@ func resizeGray16(in *image.Gray16, out *image.Gray16, scale float64, coeffs []i
 			}
 
 			offset := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*2
-			value := clampUint16(gray / sum)
+			value := clampUint16(normalize16(gray, sum))
 			out.Pix[offset+0] = uint8(value >> 8)
 			out.Pix[offset+1] = uint8(value)
 		}
@@ -370,7 +635,6 @@ func resizeYCbCr(in *ycc, out *ycc, scale float64, coeffs []int16, offset []int,
 		row := in.Pix[x*in.Stride:]
 		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
 			var p [3]int32
-			var sum int32
 			start := offset[y]
 			ci := y * filterLength
 			for i := 0; i < filterLength; i++ {
@@ -388,14 +652,13 @@ func resizeYCbCr(in *ycc, out *ycc, scale float64, coeffs []int16, offset []int,
 					p[0] += int32(coeff) * int32(row[xi+0])
 					p[1] += int32(coeff) * int32(row[xi+1])
 					p[2] += int32(coeff) * int32(row[xi+2])
-					sum += int32(coeff)
 				}
 			}
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*3
-			out.Pix[xo+0] = clampUint8(p[0] / sum)
-			out.Pix[xo+1] = clampUint8(p[1] / sum)
-			out.Pix[xo+2] = clampUint8(p[2] / sum)
+			out.Pix[xo+0] = clampUint8(normalize8(p[0]))
+			out.Pix[xo+1] = clampUint8(normalize8(p[1]))
+			out.Pix[xo+2] = clampUint8(normalize8(p[2]))
 		}
 	}
 }
@@ -436,3 +699,169 @@ func nearestYCbCr(in *ycc, out *ycc, scale float64, coeffs []bool, offset []int,
 		}
 	}
 }
+
+func resizeGenericNRGBA(in image.Image, out *image.NRGBA64, scale float64, coeffs []int32, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgb, premul [3]int64
+			var alpha, sum int64
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case xi < 0:
+						xi = 0
+					case xi >= maxX:
+						xi = maxX
+					}
+
+					c, ok := in.At(xi+in.Bounds().Min.X, x+in.Bounds().Min.Y).(color.NRGBA)
+					if !ok {
+						c = color.NRGBAModel.Convert(in.At(xi+in.Bounds().Min.X, x+in.Bounds().Min.Y)).(color.NRGBA)
+					}
+					r, g, b, a := int64(c.R)*0x101, int64(c.G)*0x101, int64(c.B)*0x101, int64(c.A)*0x101
+
+					// Straight colors are weighted with their alpha
+					rgb[0] += int64(coeff) * r
+					rgb[1] += int64(coeff) * g
+					rgb[2] += int64(coeff) * b
+					premul[0] += int64(coeff) * r * a
+					premul[1] += int64(coeff) * g * a
+					premul[2] += int64(coeff) * b * a
+					alpha += int64(coeff) * a
+					sum += int64(coeff)
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
+			setStraight(out.Pix[xo:], rgb, premul, alpha, sum)
+		}
+	}
+}
+
+func resizeNRGBA64Straight(in *image.NRGBA64, out *image.NRGBA64, scale float64, coeffs []int32, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgb, premul [3]int64
+			var alpha, sum int64
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case uint(xi) < uint(maxX):
+						xi *= 8
+					case xi >= maxX:
+						xi = 8 * maxX
+					default:
+						xi = 0
+					}
+
+					r := int64(uint16(row[xi+0])<<8 | uint16(row[xi+1]))
+					g := int64(uint16(row[xi+2])<<8 | uint16(row[xi+3]))
+					b := int64(uint16(row[xi+4])<<8 | uint16(row[xi+5]))
+					a := int64(uint16(row[xi+6])<<8 | uint16(row[xi+7]))
+
+					// Straight colors are weighted with their alpha
+					rgb[0] += int64(coeff) * r
+					rgb[1] += int64(coeff) * g
+					rgb[2] += int64(coeff) * b
+					premul[0] += int64(coeff) * r * a
+					premul[1] += int64(coeff) * g * a
+					premul[2] += int64(coeff) * b * a
+					alpha += int64(coeff) * a
+					sum += int64(coeff)
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
+			setStraight(out.Pix[xo:], rgb, premul, alpha, sum)
+		}
+	}
+}
+
+// setStraight writes a non-premultiplied 16-bit pixel. The color is the
+// alpha-weighted average of the taps, or the plain average if all taps
+// are fully transparent.
+func setStraight(pix []uint8, rgb, premul [3]int64, alpha, sum int64) {
+	for c := 0; c < 3; c++ {
+		var value uint16
+		if alpha > 0 {
+			value = clampUint16(normalize16(premul[c], alpha))
+		} else {
+			value = clampUint16(normalize16(rgb[c], sum))
+		}
+		pix[2*c+0] = uint8(value >> 8)
+		pix[2*c+1] = uint8(value)
+	}
+	value := clampUint16(normalize16(alpha, sum))
+	pix[6] = uint8(value >> 8)
+	pix[7] = uint8(value)
+}
+
+func resizeYCbCrSeparate(in *ycc, out *ycc, scale float64, lumaCoeffs []int16, lumaOffset []int, lumaLength int, chromaCoeffs []int16, chromaOffset []int, chromaLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var luma int32
+			start := lumaOffset[y]
+			ci := y * lumaLength
+			for i := 0; i < lumaLength; i++ {
+				coeff := lumaCoeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case uint(xi) < uint(maxX):
+						xi *= 3
+					case xi >= maxX:
+						xi = 3 * maxX
+					default:
+						xi = 0
+					}
+					luma += int32(coeff) * int32(row[xi+0])
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*3
+			out.Pix[xo+0] = clampUint8(normalize8(luma))
+
+			var chroma [2]int32
+			start = chromaOffset[y]
+			ci = y * chromaLength
+			for i := 0; i < chromaLength; i++ {
+				coeff := chromaCoeffs[ci+i]
+				if coeff != 0 {
+					xi := start + i
+					switch {
+					case uint(xi) < uint(maxX):
+						xi *= 3
+					case xi >= maxX:
+						xi = 3 * maxX
+					default:
+						xi = 0
+					}
+					chroma[0] += int32(coeff) * int32(row[xi+1])
+					chroma[1] += int32(coeff) * int32(row[xi+2])
+				}
+			}
+
+			out.Pix[xo+1] = clampUint8(normalize8(chroma[0]))
+			out.Pix[xo+2] = clampUint8(normalize8(chroma[1]))
+		}
+	}
+}
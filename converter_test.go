@@ -1,6 +1,9 @@
 package resize
 
 import (
+	"image"
+	"image/color"
+	"reflect"
 	"testing"
 )
 
@@ -41,3 +44,194 @@ func Test_ClampUint16(t *testing.T) {
 		}
 	}
 }
+
+// nrgbaImage hides the concrete type of an *image.NRGBA.
+type nrgbaImage struct {
+	*image.NRGBA
+}
+
+func Test_GenericNRGBAKeepsColor(t *testing.T) {
+	m := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	c := color.NRGBA{0xff, 0xc0, 0x10, 0x03}
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			m.SetNRGBA(x, y, c)
+		}
+	}
+	img := nrgbaImage{m}
+
+	out, ok := Resize(4, 4, img, Lanczos3).(*image.NRGBA64)
+	if !ok {
+		t.Fatalf("want *image.NRGBA64, got %T", out)
+	}
+	want := color.NRGBA64{0xffff, 0xc0c0, 0x1010, 0x0303}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if got := out.NRGBA64At(x, y); got != want {
+				t.Fatalf("(%d,%d): want %v, got %v", x, y, want, got)
+			}
+		}
+	}
+}
+
+func Test_ResizeNRGBAPartlyOpaque(t *testing.T) {
+	// Opaque colors on the left, transparent garbage on the right.
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	opaque := image.NewRGBA(img.Rect)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if x < 20 {
+				c := color.NRGBA{0x30, uint8(5 * y), 0xc0, 0xff}
+				img.SetNRGBA(x, y, c)
+				opaque.Set(x, y, c)
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{0xff, 0xff, 0, 0})
+			}
+		}
+	}
+
+	got := Resize(16, 8, img, Bilinear).(*image.RGBA)
+	want := Resize(16, 8, opaque, Bilinear).(*image.RGBA)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 16; x++ {
+			c := got.RGBAAt(x, y)
+			if x < 6 && c != want.RGBAAt(x, y) {
+				t.Errorf("(%d,%d): opaque region: want %v, got %v", x, y, want.RGBAAt(x, y), c)
+			}
+			if c.A < 0x20 {
+				continue
+			}
+			// Transparent pixels do not change the color of the edge,
+			// up to the precision of nearly transparent pixels.
+			n := color.NRGBAModel.Convert(c).(color.NRGBA)
+			if absDiff(uint32(n.R), 0x30) > 3 || absDiff(uint32(n.B), 0xc0) > 3 {
+				t.Errorf("(%d,%d): transparent color bleeds into %v", x, y, n)
+			}
+		}
+	}
+}
+
+func Test_ResizeNRGBAHalfTransparent(t *testing.T) {
+	// A half transparent color next to transparent black.
+	img := image.NewNRGBA(image.Rect(0, 0, 48, 16))
+	c := color.NRGBA{0xc8, 0x65, 0x33, 0x80}
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m := Resize(20, 7, img, interp).(*image.RGBA)
+		for y := 0; y < 7; y++ {
+			for x := 0; x < 20; x++ {
+				p := m.RGBAAt(x, y)
+				if p.A < 0x40 {
+					continue
+				}
+				// Straight color does not darken towards the
+				// transparent region.
+				n := color.NRGBAModel.Convert(p).(color.NRGBA)
+				if absDiff(uint32(n.R), uint32(c.R)) > 1 || absDiff(uint32(n.G), uint32(c.G)) > 1 || absDiff(uint32(n.B), uint32(c.B)) > 1 {
+					t.Errorf("interp %v (%d,%d): want %v, got %v", interp, x, y, c, n)
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeNRGBAAlphaGradient(t *testing.T) {
+	// Transparent black, then white fading in to opaque.
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 8))
+	for y := 0; y < 8; y++ {
+		for x := 16; x < 64; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{0xff, 0xff, 0xff, uint8((x - 16) * 255 / 47)})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m := Resize(16, 2, img, interp).(*image.RGBA)
+		for x := 0; x < 16; x++ {
+			p := m.RGBAAt(x, 1)
+			if p.A == 0 {
+				// Colors are premultiplied, so transparency is black.
+				if p != (color.RGBA{}) {
+					t.Errorf("interp %v x=%d: want transparent black, got %v", interp, x, p)
+				}
+				continue
+			}
+			if p.A < 0x20 {
+				continue
+			}
+			if n := color.NRGBAModel.Convert(p).(color.NRGBA); n.R < 0xfc || n.G < 0xfc || n.B < 0xfc {
+				t.Errorf("interp %v x=%d: white darkens to %v", interp, x, n)
+			}
+		}
+	}
+}
+
+func Test_ResizeNRGBA64KeepsColor(t *testing.T) {
+	// The result is premultiplied, so colors are kept within 1 for at
+	// least half opaque pixels.
+	for _, c := range []color.NRGBA64{
+		{0x1234, 0x8765, 0xfedc, 0xffff},
+		{0x1234, 0x8765, 0xfedc, 0x8000},
+		{0x0101, 0xfefe, 0x7f80, 0x8001},
+	} {
+		img := image.NewNRGBA64(image.Rect(0, 0, 40, 30))
+		for y := 0; y < 30; y++ {
+			for x := 0; x < 40; x++ {
+				img.SetNRGBA64(x, y, c)
+			}
+		}
+		m := Resize(20, 15, img, Lanczos3)
+		for y := 0; y < 15; y++ {
+			for x := 0; x < 20; x++ {
+				n := color.NRGBA64Model.Convert(m.At(x, y)).(color.NRGBA64)
+				if absDiff(uint32(n.R), uint32(c.R)) > 1 || absDiff(uint32(n.G), uint32(c.G)) > 1 ||
+					absDiff(uint32(n.B), uint32(c.B)) > 1 || n.A != c.A {
+					t.Fatalf("(%d,%d): want %v, got %v", x, y, c, n)
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeNYCbCrA(t *testing.T) {
+	img := image.NewNYCbCrA(image.Rect(1, 2, 41, 32), image.YCbCrSubsampleRatio420)
+	for i := range img.Y {
+		img.Y[i] = uint8(i * 7)
+	}
+	for i := range img.Cb {
+		img.Cb[i], img.Cr[i] = uint8(i*5), uint8(i*11)
+	}
+	// The left half is opaque, the right half half transparent.
+	for y := 2; y < 32; y++ {
+		for x := 1; x < 41; x++ {
+			a := uint8(0xff)
+			if x >= 21 {
+				a = 0x80
+			}
+			img.A[img.AOffset(x, y)] = a
+		}
+	}
+	rgba64 := image.NewRGBA64(image.Rect(0, 0, 40, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			rgba64.Set(x, y, img.At(x+1, y+2))
+		}
+	}
+
+	m := Resize(10, 6, img, Bilinear).(*image.RGBA64)
+	if want := Resize(10, 6, rgba64, Bilinear); !reflect.DeepEqual(m, want) {
+		t.Error("differs from the RGBA64 result")
+	}
+	for y := 0; y < 6; y++ {
+		if a := m.RGBA64At(1, y).A; a != 0xffff {
+			t.Errorf("opaque pixel (1,%d) has alpha %#x", y, a)
+		}
+		if a := m.RGBA64At(8, y).A; a != 0x8080 {
+			t.Errorf("half transparent pixel (8,%d) has alpha %#x", y, a)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"math"
+)
+
+// depthMinSupport is the least share of the kernel weight that has to fall
+// on valid pixels for an output pixel of ResizeDepth to be valid.
+const depthMinSupport = 0.5
+
+// ResizeDepth scales a depth map, skipping pixels with the value invalid and
+// setting output pixels without enough valid taps to invalid.
+func ResizeDepth(width, height uint, invalid uint16, img *image.Gray16, interp InterpolationFunction) *image.Gray16 {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	// Depth is stored premultiplied by its validity, so a plain convolution
+	// of both channels followed by a division is a normalized convolution.
+	in := newFloatImage(image.Rect(0, 0, b.Dx(), b.Dy()), 2)
+	lo, hi := float32(math.Inf(1)), float32(math.Inf(-1))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			i := img.PixOffset(x+b.Min.X, y+b.Min.Y)
+			v := uint16(img.Pix[i+0])<<8 | uint16(img.Pix[i+1])
+			if v != invalid {
+				o := in.PixOffset(x, y)
+				in.Pix[o+0] = float32(v)
+				in.Pix[o+1] = 1
+				if float32(v) < lo {
+					lo = float32(v)
+				}
+				if float32(v) > hi {
+					hi = float32(v)
+				}
+			}
+		}
+	}
+
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+
+	result := image.NewGray16(image.Rect(0, 0, int(width), int(height)))
+	for y := 0; y < int(height); y++ {
+		for x := 0; x < int(width); x++ {
+			o := out.PixOffset(x, y)
+			v := invalid
+			if w := out.Pix[o+1]; w >= depthMinSupport {
+				d := out.Pix[o+0] / w
+				if d < lo {
+					d = lo
+				}
+				if d > hi {
+					d = hi
+				}
+				v = depthValue(d, invalid)
+			}
+			i := result.PixOffset(x, y)
+			result.Pix[i+0] = uint8(v >> 8)
+			result.Pix[i+1] = uint8(v)
+		}
+	}
+	return result
+}
+
+// depthValue converts a valid interpolated depth to uint16. A result that
+// would collide with the invalid marker is moved by one step.
+func depthValue(x float32, invalid uint16) uint16 {
+	var v uint16
+	if x > 0 {
+		v = floatToUint16(x + 0.5)
+	}
+	if v == invalid {
+		if invalid == 0xffff {
+			v--
+		} else {
+			v++
+		}
+	}
+	return v
+}
+
+// ResizeWithDepth scales an image like Resize together with its srcW x srcH
+// depth buffer, which is sampled from the nearest source pixel. If the sizes
+// do not match, img and depth are returned unchanged.
+func ResizeWithDepth(width, height uint, img image.Image, depth []float32, srcW, srcH int, interp InterpolationFunction) (image.Image, []float32) {
+	if b := img.Bounds(); b.Dx() != srcW || b.Dy() != srcH || len(depth) < srcW*srcH {
+		return img, depth
+	}
+	width, height, scaleX, scaleY := scaledSize(width, height, srcW, srcH)
+
+	result := Resize(width, height, img, interp)
+	if srcW <= 0 || srcH <= 0 {
+		return result, depth
+	}
+
+	out := make([]float32, int(width)*int(height))
+	xs := make([]int, width)
+	for x := range xs {
+		xs[x] = nearestIndex(x, scaleX, srcW)
+	}
+	for y := 0; y < int(height); y++ {
+		row := depth[nearestIndex(y, scaleY, srcH)*srcW:]
+		for x, xi := range xs {
+			out[y*int(width)+x] = row[xi]
+		}
+	}
+	return result, out
+}
+
+// nearestIndex returns the source pixel under the center of output pixel i.
+func nearestIndex(i int, scale float64, size int) int {
+	return replicateBorder(int((float64(i)+0.5)*scale), size)
+}
@@ -0,0 +1,122 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeDepthKeepsEdgeSharp(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 20; x++ {
+			img.SetGray16(x, y, color.Gray16{1000})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		for _, size := range []uint{15, 70} {
+			out := ResizeDepth(size, size, 0, img, interp)
+			if out.Bounds() != image.Rect(0, 0, int(size), int(size)) {
+				t.Fatalf("%v: got bounds %v", interp, out.Bounds())
+			}
+			for y := 0; y < int(size); y++ {
+				for x := 0; x < int(size); x++ {
+					v := out.Gray16At(x, y).Y
+					if v != 1000 && v != 0 {
+						t.Fatalf("%v %d: depth bled at (%d,%d): %d", interp, size, x, y, v)
+					}
+				}
+			}
+			if out.Gray16At(0, 0).Y != 1000 || out.Gray16At(int(size)-1, 0).Y != 0 {
+				t.Errorf("%v %d: valid or invalid region lost", interp, size)
+			}
+		}
+	}
+}
+
+func Test_ResizeDepthAvoidsInvalidValue(t *testing.T) {
+	if v := depthValue(0.2, 0); v != 1 {
+		t.Errorf("want 1, got %d", v)
+	}
+	if v := depthValue(65535, 0xffff); v != 0xfffe {
+		t.Errorf("want 0xfffe, got %d", v)
+	}
+}
+
+func Test_ResizeWithDepth(t *testing.T) {
+	// A red surface at depth 1 in front of a blue one at depth 10.
+	img := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	depth := make([]float32, 60*40)
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			if x < 23 {
+				img.SetRGBA(x, y, color.RGBA{0xff, 0, 0, 0xff})
+				depth[y*60+x] = 1
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 0xff, 0xff})
+				depth[y*60+x] = 10
+			}
+		}
+	}
+
+	m, d := ResizeWithDepth(25, 0, img, depth, 60, 40, Bilinear)
+	b := m.Bounds()
+	if len(d) != b.Dx()*b.Dy() {
+		t.Fatalf("want %d depth values for %v, got %d", b.Dx()*b.Dy(), b, len(d))
+	}
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			v := d[y*b.Dx()+x]
+			if v != 1 && v != 10 {
+				t.Fatalf("(%d,%d): depth %g is blended", x, y, v)
+			}
+			// The depth follows the color that dominates the pixel.
+			r, _, bl, _ := m.At(x, y).RGBA()
+			if (r > bl) != (v == 1) {
+				t.Errorf("(%d,%d): depth %g does not match color %v", x, y, v, m.At(x, y))
+			}
+		}
+	}
+}
+
+func Test_ResizeDepthStaysInRange(t *testing.T) {
+	// A steep depth edge next to a region without data.
+	img := image.NewGray16(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 24; x++ {
+			v := uint16(1000)
+			if x >= 16 {
+				v = 60000
+			}
+			img.SetGray16(x, y, color.Gray16{v})
+		}
+	}
+
+	for _, size := range []uint{15, 70} {
+		out := ResizeDepth(size, size, 0, img, Lanczos3)
+		for y := 0; y < int(size); y++ {
+			for x := 0; x < int(size); x++ {
+				if v := out.Gray16At(x, y).Y; v != 0 && (v < 1000 || v > 60000) {
+					t.Fatalf("%d: depth %d at (%d,%d) is outside of the input range", size, v, x, y)
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeWithDepthMismatch(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for _, c := range []struct {
+		depth      []float32
+		srcW, srcH int
+	}{
+		{make([]float32, 60*40-1), 60, 40},
+		{make([]float32, 60*40), 40, 60},
+	} {
+		m, d := ResizeWithDepth(25, 0, img, c.depth, c.srcW, c.srcH, Bilinear)
+		if m != image.Image(img) || len(d) != len(c.depth) {
+			t.Errorf("%dx%d with %d values: want the input unchanged", c.srcW, c.srcH, len(c.depth))
+		}
+	}
+}
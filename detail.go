@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// detailSigma is the radius of the blur that separates the detail of the
+// source in ResizeDetailPreserve.
+const detailSigma = 1
+
+// ResizeDetailPreserve enlarges an image with Lanczos3 and adds back strength
+// times the fine detail of the source.
+func ResizeDetailPreserve(width, height uint, img image.Image, strength float32) image.Image {
+	b := img.Bounds()
+	if strength == 0 || b.Dx() <= 0 || b.Dy() <= 0 {
+		return Resize(width, height, img, Lanczos3)
+	}
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	in := newFloatImageFromImage(img)
+	blurred := newFloatImage(in.Rect, 4)
+	temp := newFloatImage(image.Rect(0, 0, b.Dy(), b.Dx()), 4)
+	taps, kernel := gaussian(detailSigma)
+	blurFloat(in, blurred, temp, taps, kernel)
+	for i, v := range in.Pix {
+		blurred.Pix[i] = v - blurred.Pix[i]
+	}
+
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, Lanczos3)
+	detail := resizeFloat(blurred, int(width), int(height), scaleX, scaleY, Lanczos3)
+	for i := 0; i < len(out.Pix); i += 4 {
+		a := out.Pix[i+3] + strength*detail.Pix[i+3]
+		if a > 0xffff {
+			a = 0xffff
+		}
+		out.Pix[i+3] = a
+		for c := 0; c < 3; c++ {
+			// Keep the colors premultiplied.
+			if v := out.Pix[i+c] + strength*detail.Pix[i+c]; v < a {
+				out.Pix[i+c] = v
+			} else {
+				out.Pix[i+c] = a
+			}
+		}
+	}
+	return out.RGBA64()
+}
+
+// sharpenSigma is the radius of the blur that ResizeAdaptiveSharpen
+// subtracts, and sharpenEdge the gradient per pixel, in 16-bit units, at
+// which it sharpens with the full amount.
+const (
+	sharpenSigma = 1
+	sharpenEdge  = 0x2000
+)
+
+// ResizeAdaptiveSharpen scales an image like Resize and sharpens it by amount
+// in proportion to its local gradient.
+func ResizeAdaptiveSharpen(width, height uint, img image.Image, interp InterpolationFunction, amount float32) image.Image {
+	b := img.Bounds()
+	if amount == 0 || b.Dx() <= 0 || b.Dy() <= 0 {
+		return Resize(width, height, img, interp)
+	}
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	out := resizeFloat(newFloatImageFromImage(img), int(width), int(height), scaleX, scaleY, interp)
+	blurred := newFloatImage(out.Rect, 4)
+	temp := newFloatImage(image.Rect(0, 0, out.Rect.Dy(), out.Rect.Dx()), 4)
+	taps, kernel := gaussian(sharpenSigma)
+	blurFloat(out, blurred, temp, taps, kernel)
+
+	w, h := out.Rect.Dx(), out.Rect.Dy()
+	result := newFloatImage(out.Rect, 4)
+	parallelRows(h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < w; x++ {
+				i := out.PixOffset(x, y)
+				weight := amount * gradientFloat(out, x, y) / sharpenEdge
+				if weight > amount {
+					weight = amount
+				}
+				a := out.Pix[i+3] + weight*(out.Pix[i+3]-blurred.Pix[i+3])
+				if a > 0xffff {
+					a = 0xffff
+				}
+				result.Pix[i+3] = a
+				for c := 0; c < 3; c++ {
+					// Keep the colors premultiplied.
+					if v := out.Pix[i+c] + weight*(out.Pix[i+c]-blurred.Pix[i+c]); v < a {
+						result.Pix[i+c] = v
+					} else {
+						result.Pix[i+c] = a
+					}
+				}
+			}
+		}
+	})
+	return result.RGBA64()
+}
+
+// Unsharp sharpens an image by adding amount times its difference to a
+// Gaussian blur with standard deviation radius.
+func Unsharp(img image.Image, radius, amount float64) image.Image {
+	b := img.Bounds()
+	if amount == 0 || radius <= 0 || b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	in := newFloatImageFromImage(img)
+	blurred := newFloatImage(in.Rect, 4)
+	temp := newFloatImage(image.Rect(0, 0, b.Dy(), b.Dx()), 4)
+	taps, kernel := gaussian(radius)
+	blurFloat(in, blurred, temp, taps, kernel)
+
+	k := float32(amount)
+	for i := 0; i < len(in.Pix); i += 4 {
+		a := in.Pix[i+3] + k*(in.Pix[i+3]-blurred.Pix[i+3])
+		if a > 0xffff {
+			a = 0xffff
+		}
+		blurred.Pix[i+3] = a
+		for c := 0; c < 3; c++ {
+			// Keep the colors premultiplied.
+			if v := in.Pix[i+c] + k*(in.Pix[i+c]-blurred.Pix[i+c]); v < a {
+				blurred.Pix[i+c] = v
+			} else {
+				blurred.Pix[i+c] = a
+			}
+		}
+	}
+	return blurred.RGBA64()
+}
+
+// gradientFloat returns the largest central difference of any channel of
+// p at (x, y), horizontally or vertically. Pixels beyond the border are
+// replicated.
+func gradientFloat(p *floatImage, x, y int) float32 {
+	w, h := p.Rect.Dx(), p.Rect.Dy()
+	l := p.PixOffset(replicateBorder(x-1, w), y)
+	r := p.PixOffset(replicateBorder(x+1, w), y)
+	t := p.PixOffset(x, replicateBorder(y-1, h))
+	d := p.PixOffset(x, replicateBorder(y+1, h))
+	var g float32
+	for c := 0; c < p.Channels; c++ {
+		dx := (p.Pix[r+c] - p.Pix[l+c]) / 2
+		dy := (p.Pix[d+c] - p.Pix[t+c]) / 2
+		if dx < 0 {
+			dx = -dx
+		}
+		if dy < 0 {
+			dy = -dy
+		}
+		if dx > g {
+			g = dx
+		}
+		if dy > g {
+			g = dy
+		}
+	}
+	return g
+}
@@ -0,0 +1,120 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// maxStep returns the largest difference of the red channel of horizontally
+// adjacent pixels in row y.
+func maxStep(img image.Image, y int) uint32 {
+	b := img.Bounds()
+	var step uint32
+	for x := b.Min.X; x < b.Max.X-1; x++ {
+		r0, _, _, _ := img.At(x, y).RGBA()
+		r1, _, _, _ := img.At(x+1, y).RGBA()
+		if d := absDiff(r0, r1); d > step {
+			step = d
+		}
+	}
+	return step
+}
+
+func Test_ResizeDetailPreserve(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			v := uint8(0x40)
+			if x >= 10 {
+				v = 0xc0
+			}
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 0xff})
+		}
+	}
+
+	plain := Resize(80, 40, img, Lanczos3)
+	if m := ResizeDetailPreserve(80, 40, img, 0); !reflect.DeepEqual(m, plain) {
+		t.Error("strength 0 differs from Lanczos3")
+	}
+
+	sharp := ResizeDetailPreserve(80, 40, img, 0.5)
+	if sharp.Bounds() != plain.Bounds() {
+		t.Fatalf("want bounds %v, got %v", plain.Bounds(), sharp.Bounds())
+	}
+	if s, p := maxStep(sharp, 20), maxStep(plain, 20); s <= p {
+		t.Errorf("edge contrast not increased: %d <= %d", s, p)
+	}
+}
+
+func Test_ResizeAdaptiveSharpen(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 80, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 80; x++ {
+			v := uint8(0x40)
+			if x >= 40 {
+				v = 0xc0
+			}
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 0xff})
+		}
+	}
+
+	plain := Resize(40, 10, img, Lanczos3)
+	if m := ResizeAdaptiveSharpen(40, 10, img, Lanczos3, 0); !reflect.DeepEqual(m, plain) {
+		t.Error("amount 0 differs from Resize")
+	}
+
+	sharp := ResizeAdaptiveSharpen(40, 10, img, Lanczos3, 1)
+	if sharp.Bounds() != plain.Bounds() {
+		t.Fatalf("want bounds %v, got %v", plain.Bounds(), sharp.Bounds())
+	}
+	if s, p := maxStep(sharp, 5), maxStep(plain, 5); s <= p {
+		t.Errorf("edge contrast not increased: %d <= %d", s, p)
+	}
+	// Away from the edge the result is flat and not changed.
+	for _, x := range []int{0, 5, 12, 27, 34, 39} {
+		want := uint32(0x4040)
+		if x >= 20 {
+			want = 0xc0c0
+		}
+		if r, _, _, _ := sharp.At(x, 5).RGBA(); r != want {
+			t.Errorf("x=%d: want %#x, got %#x", x, want, r)
+		}
+	}
+}
+
+func Test_Unsharp(t *testing.T) {
+	img := image.NewRGBA(image.Rect(3, 2, 43, 12))
+	for y := 2; y < 12; y++ {
+		for x := 3; x < 43; x++ {
+			v := uint8(0x40)
+			if x >= 23 {
+				v = 0xc0
+			}
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 0xff})
+		}
+	}
+
+	if m := Unsharp(img, 1, 0); m != image.Image(img) {
+		t.Error("amount 0 changes the image")
+	}
+
+	sharp := Unsharp(img, 1, 1)
+	if sharp.Bounds() != image.Rect(0, 0, 40, 10) {
+		t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 40, 10), sharp.Bounds())
+	}
+	if s, p := maxStep(sharp, 5), maxStep(img, 7); s <= p {
+		t.Errorf("edge contrast not increased: %d <= %d", s, p)
+	}
+	// Away from the edge the image is flat and not changed.
+	for _, x := range []int{0, 5, 12, 27, 34, 39} {
+		want := uint32(0x4040)
+		if x >= 20 {
+			want = 0xc0c0
+		}
+		if r, _, _, _ := sharp.At(x, 5).RGBA(); r != want {
+			t.Errorf("x=%d: want %#x, got %#x", x, want, r)
+		}
+	}
+}
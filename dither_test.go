@@ -0,0 +1,44 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeDitherSeeded(t *testing.T) {
+	// A gradient too shallow for 8 bits.
+	img := image.NewRGBA64(image.Rect(0, 0, 200, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 200; x++ {
+			v := uint16(0x4000 + 3*x*y)
+			img.SetRGBA64(x, y, color.RGBA64{v, v, v, 0xffff})
+		}
+	}
+
+	a := ResizeDitherSeeded(100, 10, img, Bilinear, 1)
+	b := ResizeDitherSeeded(100, 10, img, Bilinear, 1)
+	c := ResizeDitherSeeded(100, 10, img, Bilinear, 2)
+	if !bytes.Equal(a.Pix, b.Pix) {
+		t.Error("same seed gives different results")
+	}
+	if bytes.Equal(a.Pix, c.Pix) {
+		t.Error("different seeds give the same result")
+	}
+
+	// The dithered image keeps the mean of the gradient.
+	plain := resizeFloat(newFloatImageFromImage(img), 100, 10, 2, 2, Bilinear)
+	var sum, want float64
+	for i := 0; i < len(a.Pix); i += 4 {
+		if a.Pix[i+3] != 0xff || a.Pix[i] > a.Pix[i+3] {
+			t.Fatalf("sample %d: invalid color %v", i, a.Pix[i:i+4])
+		}
+		sum += float64(a.Pix[i])
+		want += float64(plain.Pix[i]) / 0x101
+	}
+	n := float64(len(a.Pix) / 4)
+	if d := (sum - want) / n; d < -0.1 || d > 0.1 {
+		t.Errorf("mean differs by %g", d)
+	}
+}
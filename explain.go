@@ -0,0 +1,248 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Tap is a single source pixel contributing to an output pixel.
+// X and Y are coordinates in the source image, Weight is the normalized
+// contribution of that pixel.
+type Tap struct {
+	X, Y   int
+	Weight float64
+}
+
+// ExplainPixel returns the source taps that Resize combines into the output
+// pixel (outX, outY) when called with the same arguments. The weights are
+// taken from the same tables Resize uses and sum to 1. Taps that are clamped
+// to the image border are merged into a single entry.
+// Nil is returned if the output pixel is out of range.
+func ExplainPixel(width, height uint, img image.Image, interp InterpolationFunction, outX, outY int) []Tap {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+	if b.Dx() <= 0 || b.Dy() <= 0 || outX < 0 || outY < 0 || outX >= int(width) || outY >= int(height) {
+		return nil
+	}
+
+	// Trivial case: Resize returns the input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return []Tap{{outX + b.Min.X, outY + b.Min.Y, 1}}
+	}
+
+	xs, wx := explainAxis(outX, int(width), b.Dx(), scaleX, img, interp)
+	ys, wy := explainAxis(outY, int(height), b.Dy(), scaleY, img, interp)
+
+	taps := make([]Tap, 0, len(xs)*len(ys))
+	for j, y := range ys {
+		for i, x := range xs {
+			taps = append(taps, Tap{x + b.Min.X, y + b.Min.Y, wx[i] * wy[j]})
+		}
+	}
+	return taps
+}
+
+// explainAxis returns the clamped source indices and normalized weights
+// used for output index out along one axis.
+func explainAxis(out, dy, size int, scale float64, img image.Image, interp InterpolationFunction) ([]int, []float64) {
+	taps, kernel := interp.kernel()
+	maxX := size - 1
+
+	var coeffs []float64
+	var offset []int
+	var filterLength int
+	switch {
+	case interp == NearestNeighbor:
+		var c []bool
+		c, offset, filterLength = createWeightsNearest(dy, taps, blur, scale)
+		for _, v := range c[out*filterLength : (out+1)*filterLength] {
+			if v {
+				coeffs = append(coeffs, 1)
+			} else {
+				coeffs = append(coeffs, 0)
+			}
+		}
+	case is8Bit(img):
+		var c []int16
+		c, offset, filterLength = createWeights8(dy, taps, blur, scale, kernel)
+		for _, v := range c[out*filterLength : (out+1)*filterLength] {
+			coeffs = append(coeffs, float64(v))
+		}
+	default:
+		var c []int32
+		c, offset, filterLength = createWeights16(dy, taps, blur, scale, kernel)
+		for _, v := range c[out*filterLength : (out+1)*filterLength] {
+			coeffs = append(coeffs, float64(v))
+		}
+	}
+
+	var index []int
+	var weight []float64
+	var sum float64
+	for i, coeff := range coeffs {
+		if coeff == 0 {
+			continue
+		}
+		xi := offset[out] + i
+		switch {
+		case xi < 0:
+			xi = 0
+		case xi >= maxX:
+			xi = maxX
+		}
+		sum += coeff
+		if n := len(index); n > 0 && index[n-1] == xi {
+			weight[n-1] += coeff
+			continue
+		}
+		index = append(index, xi)
+		weight = append(weight, coeff)
+	}
+	for i := range weight {
+		weight[i] /= sum
+	}
+	return index, weight
+}
+
+// is8Bit reports whether Resize processes img with 8-bit precision.
+func is8Bit(img image.Image) bool {
+	switch img.(type) {
+	case *image.RGBA, *image.NRGBA, *image.YCbCr, *image.Gray, *image.Alpha, *image.CMYK:
+		return true
+	}
+	return false
+}
+
+// ResizeReport describes how Resize processes an image, as returned by
+// ResizeInfo.
+type ResizeReport struct {
+	// Converter names the pixel type the resize is specialized for, e.g.
+	// "*image.RGBA", or "generic" for images that are read through At.
+	// It is empty if Resize returns the input image unchanged.
+	Converter string
+	// Width and Height are the size of the result.
+	Width, Height int
+	// ScaleX and ScaleY are the number of source pixels per result pixel.
+	ScaleX, ScaleY float64
+	// FilterFactorX and FilterFactorY scale the kernel argument. They are
+	// below 1 when reducing, which widens the kernel to avoid aliasing.
+	FilterFactorX, FilterFactorY float64
+	// SupportX and SupportY are the number of source pixels that are read
+	// for every result pixel.
+	SupportX, SupportY int
+	// Memory is the number of bytes of the pixel buffers that are
+	// allocated, including the result. The generic converter may
+	// allocate more for every color it reads.
+	Memory int
+	// PooledMemory is the number of bytes of the temporary image that
+	// 16-bit passes take from a pool shared by all calls. It is only
+	// allocated if the pool holds no image that is large enough.
+	PooledMemory int
+}
+
+// ResizeInfo returns a report of how Resize scales img with the same
+// arguments, without doing any pixel work.
+func ResizeInfo(width, height uint, img image.Image, interp InterpolationFunction) ResizeReport {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	r := ResizeReport{
+		Width:  int(width),
+		Height: int(height),
+		ScaleX: scaleX,
+		ScaleY: scaleY,
+	}
+	if (r.Width == b.Dx() && r.Height == b.Dy()) || b.Dx() <= 0 || b.Dy() <= 0 {
+		r.Width, r.Height = b.Dx(), b.Dy()
+		return r
+	}
+
+	taps, _ := interp.kernel()
+	r.SupportX = taps * int(math.Max(math.Ceil(blur*scaleX), 1))
+	r.SupportY = taps * int(math.Max(math.Ceil(blur*scaleY), 1))
+	r.FilterFactorX = math.Min(1./(blur*scaleX), 1)
+	r.FilterFactorY = math.Min(1./(blur*scaleY), 1)
+
+	// The temporary image is transposed and has the height of the source.
+	temp, result := b.Dy()*r.Width, r.Width*r.Height
+	generic := func() {
+		r.Converter = "generic"
+		r.Memory, r.PooledMemory = 8*result, 8*temp
+	}
+	nearest := interp == NearestNeighbor
+	r.Converter = fmt.Sprintf("%T", img)
+	switch img.(type) {
+	case *image.RGBA:
+		r.Memory = 4 * (temp + result)
+		if nearest {
+			// A single pass without a temporary image.
+			r.Memory = 4 * result
+		}
+	case *image.NRGBA:
+		r.Memory = 4 * (temp + result)
+	case *image.CMYK:
+		r.Memory = 4 * (temp + result)
+		if nearest {
+			generic()
+		}
+	case *image.YCbCr:
+		// The source is converted to an interleaved copy, and the
+		// result back to planes.
+		r.Memory = 3 * (b.Dx()*b.Dy() + temp + 2*result)
+	case *image.RGBA64:
+		r.Memory, r.PooledMemory = 8*result, 8*temp
+		if !nearest {
+			// If one axis is copied unchanged, it is transposed instead
+			// of filtered, with a temporary image that is allocated.
+			p := newPlan(b.Dx(), b.Dy(), r.Width, r.Height, scaleX, scaleY, interp)
+			if identity16(p.weights16X()) || identity16(p.weights16Y()) {
+				r.Memory, r.PooledMemory = 8*(temp+result), 0
+			}
+		}
+	case *image.NRGBA64:
+		r.Memory, r.PooledMemory = 8*result, 8*temp
+		if nearest {
+			r.Memory, r.PooledMemory = 8*(temp+result), 0
+		}
+	case *image.NYCbCrA:
+		r.Memory, r.PooledMemory = 8*result, 8*temp
+		if nearest {
+			generic()
+		}
+	case *image.Paletted:
+		// The palette is converted to a table of 16-bit colors.
+		r.Memory, r.PooledMemory = 8*result+256*4*8, 8*temp
+		if nearest {
+			generic()
+		}
+	case *image.Gray, *image.Alpha:
+		r.Memory = temp + result
+	case *image.Gray16, *image.Alpha16:
+		r.Memory = 2 * (temp + result)
+	default:
+		generic()
+		if img.ColorModel() == color.NRGBAModel && !nearest {
+			r.Converter = "generic NRGBA"
+			r.Memory, r.PooledMemory = 8*(temp+result), 0
+		}
+	}
+	return r
+}
@@ -0,0 +1,149 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"math"
+	"runtime"
+	"testing"
+)
+
+func Test_ExplainPixel(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 40, 30))
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			v := uint16(x*1500 + y*700)
+			img.SetRGBA64(x, y, color.RGBA64{v, v, v, 0xffff})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+		out := Resize(13, 9, img, interp).(*image.RGBA64)
+		for _, p := range []image.Point{{0, 0}, {6, 4}, {12, 8}} {
+			taps := ExplainPixel(13, 9, img, interp, p.X, p.Y)
+			if len(taps) == 0 {
+				t.Fatalf("%v: no taps for %v", interp, p)
+			}
+			var sum, value float64
+			for _, tap := range taps {
+				sum += tap.Weight
+				value += tap.Weight * float64(img.RGBA64At(tap.X, tap.Y).R)
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("%v: weights of %v sum to %v", interp, p, sum)
+			}
+			want := float64(out.RGBA64At(p.X, p.Y).R)
+			if math.Abs(value-want) > 4 {
+				t.Errorf("%v: replayed %v as %v, want %v", interp, p, value, want)
+			}
+		}
+	}
+}
+
+func Test_ExplainPixelAlpha(t *testing.T) {
+	// Alpha masks are resized like gray images, with 8-bit weights.
+	alpha := image.NewAlpha(image.Rect(0, 0, 40, 30))
+	for i := range alpha.Pix {
+		alpha.Pix[i] = uint8(i * 7)
+	}
+	gray := &image.Gray{Pix: alpha.Pix, Stride: alpha.Stride, Rect: alpha.Rect}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		out := Resize(13, 9, alpha, interp).(*image.Alpha)
+		for _, p := range []image.Point{{0, 0}, {6, 4}, {12, 8}} {
+			taps := ExplainPixel(13, 9, alpha, interp, p.X, p.Y)
+			want := ExplainPixel(13, 9, gray, interp, p.X, p.Y)
+			if len(taps) != len(want) {
+				t.Fatalf("%v: want %v for %v, got %v", interp, want, p, taps)
+			}
+			var value float64
+			for i, tap := range taps {
+				if tap != want[i] {
+					t.Fatalf("%v: want %v for %v, got %v", interp, want, p, taps)
+				}
+				value += tap.Weight * float64(alpha.AlphaAt(tap.X, tap.Y).A)
+			}
+			value = math.Max(0, math.Min(value, 255))
+			if got := float64(out.AlphaAt(p.X, p.Y).A); math.Abs(value-got) > 1 {
+				t.Errorf("%v: replayed %v as %v, want %v", interp, p, value, got)
+			}
+		}
+	}
+}
+
+func Test_ExplainPixelOutOfRange(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 10, 10))
+	if taps := ExplainPixel(5, 5, img, Bilinear, 5, 0); taps != nil {
+		t.Errorf("want nil, got %v", taps)
+	}
+}
+
+func Test_ResizeInfoMemory(t *testing.T) {
+	imgs := []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 400, 300)),
+		image.NewGray16(image.Rect(0, 0, 400, 300)),
+		image.NewYCbCr(image.Rect(0, 0, 400, 300), image.YCbCrSubsampleRatio420),
+		image.NewRGBA64(image.Rect(0, 0, 400, 300)),
+		image.NewNRGBA64(image.Rect(0, 0, 400, 300)),
+		image.NewCMYK(image.Rect(0, 0, 400, 300)),
+		image.NewPaletted(image.Rect(0, 0, 400, 300), palette.Plan9),
+		image.NewAlpha(image.Rect(0, 0, 400, 300)),
+		image.NewAlpha16(image.Rect(0, 0, 400, 300)),
+		image.NewNYCbCrA(image.Rect(0, 0, 400, 300), image.YCbCrSubsampleRatio420),
+	}
+	for _, interp := range []InterpolationFunction{Lanczos3, NearestNeighbor} {
+		for _, img := range imgs {
+			r := ResizeInfo(150, 0, img, interp)
+			if r.Width != 150 || r.Height != 113 {
+				t.Errorf("%T: want size 150x113, got %dx%d", img, r.Width, r.Height)
+			}
+
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			Resize(150, 0, img, interp)
+			runtime.ReadMemStats(&after)
+			used := int(after.TotalAlloc - before.TotalAlloc)
+			// Weight tables are not included in the estimate, and the
+			// pooled temporary image may be reused. The generic
+			// converter allocates for the colors it reads.
+			max := r.Memory + r.PooledMemory
+			if r.Converter == "generic" {
+				max = used
+			}
+			if used < r.Memory || used > max+max/20+32<<10 {
+				t.Errorf("%s interp %v: estimated %d bytes and %d pooled, allocated %d", r.Converter, interp, r.Memory, r.PooledMemory, used)
+			}
+		}
+	}
+}
+
+func Test_ResizeInfo(t *testing.T) {
+	r := ResizeInfo(100, 50, image.NewNRGBA(image.Rect(0, 0, 400, 50)), Bilinear)
+	if r.Converter != "*image.NRGBA" {
+		t.Errorf("want converter *image.NRGBA, got %q", r.Converter)
+	}
+	if r := ResizeInfo(100, 50, image.NewPaletted(image.Rect(0, 0, 400, 50), palette.Plan9), Bilinear); r.Converter != "*image.Paletted" {
+		t.Errorf("want converter *image.Paletted, got %q", r.Converter)
+	}
+	if r.ScaleX != 4 || r.ScaleY != 1 {
+		t.Errorf("want scales 4, 1, got %g, %g", r.ScaleX, r.ScaleY)
+	}
+	if r.FilterFactorX != 0.25 || r.FilterFactorY != 1 {
+		t.Errorf("want filter factors 0.25, 1, got %g, %g", r.FilterFactorX, r.FilterFactorY)
+	}
+	if r.SupportX != 8 || r.SupportY != 2 {
+		t.Errorf("want support 8, 2, got %d, %d", r.SupportX, r.SupportY)
+	}
+
+	// Only the width changes, so the height is transposed with a temporary
+	// image that is not pooled.
+	if r := ResizeInfo(150, 300, image.NewRGBA64(image.Rect(0, 0, 400, 300)), Bilinear); r.Memory != 8*(300*150+150*300) || r.PooledMemory != 0 {
+		t.Errorf("one axis: got memory %d, pooled %d", r.Memory, r.PooledMemory)
+	}
+
+	if r := ResizeInfo(40, 30, image.NewRGBA(image.Rect(0, 0, 40, 30)), Bilinear); r.Converter != "" || r.Memory != 0 {
+		t.Errorf("unchanged image: got %+v", r)
+	}
+}
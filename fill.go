@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// Fill scales an image to cover width x height pixels and crops the center
+// of the scaled image to exactly that size, keeping the aspect ratio. It is
+// the same as FillFast; Fit scales to fit inside the box instead and does
+// not crop.
+func Fill(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	return FillFast(width, height, img, interp)
+}
+
+// FillFast scales an image to cover width x height pixels and crops the
+// center of the scaled image to exactly that size. The aspect ratio is
+// kept; the scaled image is that of Resize with a width or height of 0.
+// Only the pixels inside of the crop are computed, which saves most of the
+// work for sources with a very different aspect ratio. The result has its
+// origin at (0,0) and equals the center of the scaled image.
+// If width or height is 0, FillFast is the same as Resize.
+func FillFast(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	return CropAnchor(width, height, img, Center, interp)
+}
+
+// Anchor selects which part of a scaled image CropAnchor keeps.
+type Anchor int
+
+// Anchor constants
+const (
+	Center Anchor = iota
+	Top
+	Bottom
+	Left
+	Right
+	TopLeft
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// offset returns the position of a crop in an image that is dx pixels
+// wider and dy pixels higher than the crop.
+func (a Anchor) offset(dx, dy int) image.Point {
+	pt := image.Pt(dx/2, dy/2)
+	switch a {
+	case Left, TopLeft, BottomLeft:
+		pt.X = 0
+	case Right, TopRight, BottomRight:
+		pt.X = dx
+	}
+	switch a {
+	case Top, TopLeft, TopRight:
+		pt.Y = 0
+	case Bottom, BottomLeft, BottomRight:
+		pt.Y = dy
+	}
+	return pt
+}
+
+// CropAnchor is like FillFast, but crops the part of the scaled image at
+// anchor instead of its center, e.g. Top to keep the faces of portrait
+// photos. Only the side that overhangs is cropped, so Top and Bottom make
+// no difference for images that are wider than the box, and Left and Right
+// none for higher ones. If width or height is 0, CropAnchor is the same as
+// Resize.
+func CropAnchor(width, height uint, img image.Image, anchor Anchor, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	if width == 0 || height == 0 || b.Dx() <= 0 || b.Dy() <= 0 {
+		return Resize(width, height, img, interp)
+	}
+
+	// The side with the smaller scale factor is scaled to fit, the other
+	// one overhangs and is cropped.
+	scaledWidth, scaledHeight := width, height
+	if float64(b.Dx())*float64(height) > float64(b.Dy())*float64(width) {
+		scaledWidth = 0
+	} else {
+		scaledHeight = 0
+	}
+	scaledWidth, scaledHeight, scaleX, scaleY := scaledSize(scaledWidth, scaledHeight, b.Dx(), b.Dy())
+	crop := anchor.offset(int(scaledWidth-width), int(scaledHeight-height))
+
+	if int(scaledWidth) == b.Dx() && int(scaledHeight) == b.Dy() {
+		if img, ok := img.(imageWithSubImage); ok {
+			sub := img.SubImage(image.Rectangle{b.Min.Add(crop), b.Min.Add(crop).Add(image.Pt(int(width), int(height)))})
+			return translate(sub, image.Point{})
+		}
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(scaledWidth), int(scaledHeight), scaleX, scaleY, interp)
+	p = p.windowPlan(image.Rectangle{crop, crop.Add(image.Pt(int(width), int(height)))}, 0, b.Dy())
+	return p.apply(img)
+}
@@ -0,0 +1,150 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// fillCrop is the reference of FillFast: a full resize and a center crop.
+func fillCrop(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	var m image.Image
+	if b.Dx()*int(height) > b.Dy()*int(width) {
+		m = Resize(0, height, img, interp)
+	} else {
+		m = Resize(width, 0, img, interp)
+	}
+	mb := m.Bounds()
+	x0 := mb.Min.X + (mb.Dx()-int(width))/2
+	y0 := mb.Min.Y + (mb.Dy()-int(height))/2
+	return m.(imageWithSubImage).SubImage(image.Rect(x0, y0, x0+int(width), y0+int(height)))
+}
+
+func Test_FillFast(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 120, 30))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	gray := image.NewGray16(image.Rect(10, 10, 30, 90))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 13)
+	}
+	ycc := image.NewYCbCr(image.Rect(0, 0, 64, 40), image.YCbCrSubsampleRatio420)
+	for i := range ycc.Y {
+		ycc.Y[i] = uint8(i * 3)
+	}
+
+	sizes := []image.Point{{20, 20}, {15, 25}, {60, 10}}
+	for _, img := range []image.Image{rgba, gray, ycc} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+			for _, size := range sizes {
+				got := FillFast(uint(size.X), uint(size.Y), img, interp)
+				want := fillCrop(uint(size.X), uint(size.Y), img, interp)
+				if got.Bounds().Size() != size {
+					t.Fatalf("%T %v: want size %v, got %v", img, size, size, got.Bounds().Size())
+				}
+				gb, wb := got.Bounds(), want.Bounds()
+				for y := 0; y < size.Y; y++ {
+					for x := 0; x < size.X; x++ {
+						if g, w := got.At(gb.Min.X+x, gb.Min.Y+y), want.At(wb.Min.X+x, wb.Min.Y+y); g != w {
+							t.Fatalf("%T %v interp %v: pixel (%d,%d): want %v, got %v", img, size, interp, x, y, w, g)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_Fill(t *testing.T) {
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 400, 200), image.Rect(0, 0, 200, 400)} {
+		img := image.NewGray(r)
+		for i := range img.Pix {
+			img.Pix[i] = uint8(i * 7)
+		}
+		m := Fill(100, 100, img, Bilinear).(*image.Gray)
+		if m.Bounds().Size() != image.Pt(100, 100) {
+			t.Fatalf("%v: want size 100x100, got %v", r, m.Bounds().Size())
+		}
+		want := FillFast(100, 100, img, Bilinear).(*image.Gray)
+		if !bytes.Equal(m.Pix, want.Pix) {
+			t.Errorf("%v: result differs from FillFast", r)
+		}
+	}
+}
+
+func Test_CropAnchor(t *testing.T) {
+	landscape := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	portrait := image.NewRGBA(image.Rect(0, 0, 100, 200))
+	for _, img := range []*image.RGBA{landscape, portrait} {
+		b := img.Bounds()
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 0xff})
+			}
+		}
+	}
+
+	origins := []struct {
+		anchor              Anchor
+		landscape, portrait image.Point
+	}{
+		{Center, image.Pt(50, 0), image.Pt(0, 50)},
+		{Top, image.Pt(50, 0), image.Pt(0, 0)},
+		{Bottom, image.Pt(50, 0), image.Pt(0, 100)},
+		{Left, image.Pt(0, 0), image.Pt(0, 50)},
+		{Right, image.Pt(100, 0), image.Pt(0, 50)},
+		{TopLeft, image.Pt(0, 0), image.Pt(0, 0)},
+		{TopRight, image.Pt(100, 0), image.Pt(0, 0)},
+		{BottomLeft, image.Pt(0, 0), image.Pt(0, 100)},
+		{BottomRight, image.Pt(100, 0), image.Pt(0, 100)},
+	}
+	for _, o := range origins {
+		for _, c := range []struct {
+			img    *image.RGBA
+			origin image.Point
+		}{{landscape, o.landscape}, {portrait, o.portrait}} {
+			// Unscaled, the crop starts at the origin.
+			m := CropAnchor(100, 100, c.img, o.anchor, Bilinear)
+			mb := m.Bounds()
+			if mb != image.Rect(0, 0, 100, 100) {
+				t.Fatalf("anchor %d: want bounds (0,0)-(100,100), got %v", o.anchor, mb)
+			}
+			if r, g, _, _ := m.At(mb.Min.X, mb.Min.Y).RGBA(); int(r>>8) != c.origin.X || int(g>>8) != c.origin.Y {
+				t.Errorf("anchor %d %v: want origin %v, got (%d,%d)", o.anchor, c.img.Bounds(), c.origin, r>>8, g>>8)
+			}
+
+			// Scaled by half, the crop starts at half the origin.
+			m = CropAnchor(50, 50, c.img, o.anchor, Bilinear)
+			full := Resize(uint(c.img.Bounds().Dx()/2), uint(c.img.Bounds().Dy()/2), c.img, Bilinear)
+			mb = m.Bounds()
+			for y := 0; y < 50; y++ {
+				for x := 0; x < 50; x++ {
+					if got, want := m.At(mb.Min.X+x, mb.Min.Y+y), full.At(c.origin.X/2+x, c.origin.Y/2+y); got != want {
+						t.Fatalf("anchor %d %v: pixel (%d,%d): want %v, got %v", o.anchor, c.img.Bounds(), x, y, want, got)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Benchmark_FillFast(b *testing.B) {
+	img := image.NewRGBA(image.Rect(0, 0, 4000, 1000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FillFast(500, 500, img, Lanczos3)
+	}
+}
+
+func Benchmark_FillResizeCrop(b *testing.B) {
+	img := image.NewRGBA(image.Rect(0, 0, 4000, 1000))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fillCrop(500, 500, img, Lanczos3)
+	}
+}
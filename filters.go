@@ -27,6 +27,20 @@ func nearest(in float64) float64 {
 	return 0
 }
 
+// box is the kernel of Area. Pixels exactly on the border of the box get
+// half the weight, so that integer reductions average whole blocks of
+// pixels and ties of an enlargement are blended.
+func box(in float64) float64 {
+	in = math.Abs(in)
+	switch {
+	case in < 0.5:
+		return 1
+	case in == 0.5:
+		return 0.5
+	}
+	return 0
+}
+
 func linear(in float64) float64 {
 	in = math.Abs(in)
 	if in <= 1 {
@@ -57,6 +71,23 @@ func mitchellnetravali(in float64) float64 {
 	return 0
 }
 
+// splineKernel returns the cubic kernel of Mitchell and Netravali with the
+// parameters b and c.
+func splineKernel(b, c float64) func(float64) float64 {
+	p0, p2, p3 := 6-2*b, -18+12*b+6*c, 12-9*b-6*c
+	q0, q1, q2, q3 := 8*b+24*c, -12*b-48*c, 6*b+30*c, -b-6*c
+	return func(in float64) float64 {
+		in = math.Abs(in)
+		if in <= 1 {
+			return (p3*in*in*in + p2*in*in + p0) / 6
+		}
+		if in <= 2 {
+			return (q3*in*in*in + q2*in*in + q1*in + q0) / 6
+		}
+		return 0
+	}
+}
+
 func sinc(x float64) float64 {
 	x = math.Abs(x) * math.Pi
 	if x >= 1.220703e-4 {
@@ -79,26 +110,87 @@ func lanczos3(in float64) float64 {
 	return 0
 }
 
-// range [-256,256]
+// lanczosKernel returns the Lanczos kernel with a lobes.
+func lanczosKernel(a int) func(float64) float64 {
+	fa := float64(a)
+	return func(in float64) float64 {
+		if in > -fa && in < fa {
+			return sinc(in) * sinc(in/fa)
+		}
+		return 0
+	}
+}
+
+// gaussian returns a Gaussian kernel with standard deviation sigma and the
+// number of taps needed to cover three standard deviations.
+func gaussian(sigma float64) (int, func(float64) float64) {
+	taps := 2 * (int(math.Ceil(3*sigma)) + 1)
+	f := -0.5 / (sigma * sigma)
+	return taps, func(in float64) float64 {
+		return math.Exp(in * in * f)
+	}
+}
+
+// weightBits8 is the number of fractional bits of the weights returned by
+// createWeights8.
+const weightBits8 = 14
+
+// range [-1<<weightBits8,1<<weightBits8], summing to 1<<weightBits8
 func createWeights8(dy, filterLength int, blur, scale float64, kernel func(float64) float64) ([]int16, []int, int) {
 	filterLength = filterLength * int(math.Max(math.Ceil(blur*scale), 1))
 	filterFactor := math.Min(1./(blur*scale), 1)
 
 	coeffs := make([]int16, dy*filterLength)
 	start := make([]int, dy)
+	weights := make([]float64, filterLength)
 	for y := 0; y < dy; y++ {
 		interpX := scale*(float64(y)+0.5) - 0.5
 		start[y] = int(interpX) - filterLength/2 + 1
 		interpX -= float64(start[y])
+		var sum float64
 		for i := 0; i < filterLength; i++ {
 			in := (interpX - float64(i)) * filterFactor
-			coeffs[y*filterLength+i] = int16(kernel(in) * 256)
+			weights[i] = kernel(in)
+			sum += weights[i]
 		}
+		quantizeWeights(coeffs[y*filterLength:(y+1)*filterLength], weights, sum)
 	}
 
 	return coeffs, start, filterLength
 }
 
+// quantizeWeights stores weights divided by sum as fixed-point numbers with
+// weightBits8 fractional bits in coeffs. Rounding errors are added to the
+// largest weight, so that coeffs sum to exactly 1<<weightBits8 and pixels
+// are normalized with a shift instead of a division. Weights outside of the
+// range of int16, i.e. normalized weights of 2 or more, are saturated
+// instead of wrapping around; the coeffs then no longer sum exactly.
+func quantizeWeights(coeffs []int16, weights []float64, sum float64) {
+	var total, largest int
+	for i, w := range weights {
+		coeffs[i] = saturateInt16(math.Floor(w/sum*(1<<weightBits8) + 0.5))
+		total += int(coeffs[i])
+		if w > weights[largest] {
+			largest = i
+		}
+	}
+	coeffs[largest] = saturateInt16(float64(int(coeffs[largest]) + 1<<weightBits8 - total))
+}
+
+// saturateInt16 converts x to int16, clamping it to the range of int16.
+// NaN, which results from weights that sum to zero, becomes 0.
+func saturateInt16(x float64) int16 {
+	switch {
+	case x >= math.MaxInt16:
+		return math.MaxInt16
+	case x <= math.MinInt16:
+		return math.MinInt16
+	case x != x:
+		return 0
+	}
+	return int16(x)
+}
+
 // range [-65536,65536]
 func createWeights16(dy, filterLength int, blur, scale float64, kernel func(float64) float64) ([]int32, []int, int) {
 	filterLength = filterLength * int(math.Max(math.Ceil(blur*scale), 1))
@@ -119,6 +211,26 @@ func createWeights16(dy, filterLength int, blur, scale float64, kernel func(floa
 	return coeffs, start, filterLength
 }
 
+// range [-1,1], the sampling positions are moved by shift source pixels
+func createWeightsFloat(dy, filterLength int, blur, scale, shift float64, kernel func(float64) float64) ([]float32, []int, int) {
+	filterLength = filterLength * int(math.Max(math.Ceil(blur*scale), 1))
+	filterFactor := math.Min(1./(blur*scale), 1)
+
+	coeffs := make([]float32, dy*filterLength)
+	start := make([]int, dy)
+	for y := 0; y < dy; y++ {
+		interpX := scale*(float64(y)+0.5) - 0.5 + shift
+		start[y] = int(math.Floor(interpX)) - filterLength/2 + 1
+		interpX -= float64(start[y])
+		for i := 0; i < filterLength; i++ {
+			in := (interpX - float64(i)) * filterFactor
+			coeffs[y*filterLength+i] = float32(kernel(in))
+		}
+	}
+
+	return coeffs, start, filterLength
+}
+
 func createWeightsNearest(dy, filterLength int, blur, scale float64) ([]bool, []int, int) {
 	filterLength = filterLength * int(math.Max(math.Ceil(blur*scale), 1))
 	filterFactor := math.Min(1./(blur*scale), 1)
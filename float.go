@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// floatImage is an in memory image with an arbitrary number of float32
+// channels per pixel. It is used by resizing variants that need unclamped
+// values or channels that are not colors.
+type floatImage struct {
+	// Pix holds the image's samples. The pixel at (x, y) starts at
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*Channels].
+	Pix []float32
+	// Stride is the Pix stride (in samples) between vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+	// Channels is the number of samples per pixel.
+	Channels int
+}
+
+// newFloatImage returns a new floatImage with the given bounds and number
+// of channels.
+func newFloatImage(r image.Rectangle, channels int) *floatImage {
+	w, h := r.Dx(), r.Dy()
+	return &floatImage{
+		Pix:      make([]float32, channels*w*h),
+		Stride:   channels * w,
+		Rect:     r,
+		Channels: channels,
+	}
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *floatImage) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*p.Channels
+}
+
+// resizeFloat scales in to width x height. It uses the same two transposing
+// passes as Resize, but values are neither clamped nor quantized.
+func resizeFloat(in *floatImage, width, height int, scaleX, scaleY float64, interp InterpolationFunction) *floatImage {
+	return resizeFloatPhase(in, width, height, scaleX, scaleY, 0, 0, interp, Options{})
+}
+
+// resizeFloatPhase is like resizeFloat, but moves the sampling positions by
+// phaseX and phaseY source pixels and applies opts.
+func resizeFloatPhase(in *floatImage, width, height int, scaleX, scaleY, phaseX, phaseY float64, interp InterpolationFunction, opts Options) *floatImage {
+	temp := newFloatImage(image.Rect(0, 0, in.Rect.Dy(), width), in.Channels)
+	result := newFloatImage(image.Rect(0, 0, width, height), in.Channels)
+
+	scaleX, phaseX = opts.ConventionX.mapping(in.Rect.Dx(), width, scaleX, phaseX)
+	scaleY, phaseY = opts.ConventionY.mapping(in.Rect.Dy(), height, scaleY, phaseY)
+
+	// horizontal filter, results in transposed temporary image
+	coeffs, offset, filterLength := opts.weights(width, interp, scaleX, phaseX)
+	index := borderIndex(coeffs, offset, filterLength, in.Rect.Dx(), opts.Border)
+	convolve := opts.convolver(filterLength)
+	parallelRows(width, func(y0, y1 int) {
+		convolve(in, temp, y0, y1, coeffs, index, filterLength)
+	})
+
+	// horizontal filter on transposed image, result is not transposed
+	coeffs, offset, filterLength = opts.weights(height, interp, scaleY, phaseY)
+	index = borderIndex(coeffs, offset, filterLength, temp.Rect.Dx(), opts.Border)
+	convolve = opts.convolver(filterLength)
+	parallelRows(height, func(y0, y1 int) {
+		convolve(temp, result, y0, y1, coeffs, index, filterLength)
+	})
+	return result
+}
+
+// blurFloat filters in with kernel at its original size and writes the
+// result to out. temp must have the transposed size of in.
+func blurFloat(in, out, temp *floatImage, taps int, kernel func(float64) float64) {
+	coeffs, offset, filterLength := createWeightsFloat(in.Rect.Dx(), taps, 1, 1, 0, kernel)
+	index := borderIndex(coeffs, offset, filterLength, in.Rect.Dx(), Replicate)
+	parallelRows(in.Rect.Dx(), func(y0, y1 int) {
+		convolveFloat(in, temp, y0, y1, coeffs, index, filterLength)
+	})
+
+	coeffs, offset, filterLength = createWeightsFloat(in.Rect.Dy(), taps, 1, 1, 0, kernel)
+	index = borderIndex(coeffs, offset, filterLength, in.Rect.Dy(), Replicate)
+	parallelRows(in.Rect.Dy(), func(y0, y1 int) {
+		convolveFloat(temp, out, y0, y1, coeffs, index, filterLength)
+	})
+}
+
+// convolveFloat filters the rows of in and writes them transposed to the
+// rows [y0,y1) of out. index holds the source pixel of every tap.
+// Taps are always accumulated in the same order and every product is
+// rounded to float32 explicitly, which keeps the compiler from fusing it
+// into a multiply-add. Results are therefore bit-identical on all
+// architectures, at the cost of a few percent of speed where fused
+// instructions are available.
+func convolveFloat(in, out *floatImage, y0, y1 int, coeffs []float32, index []int, filterLength int) {
+	n := in.Channels
+
+	for x := 0; x < out.Rect.Dx(); x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := y0; y < y1; y++ {
+			xo := y*out.Stride + x*n
+			for c := 0; c < n; c++ {
+				out.Pix[xo+c] = 0
+			}
+			var sum float32
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := coeffs[ci+i]
+				if coeff != 0 {
+					xi := index[ci+i] * n
+					for c := 0; c < n; c++ {
+						out.Pix[xo+c] += float32(coeff * row[xi+c])
+					}
+					sum += coeff
+				}
+			}
+			for c := 0; c < n; c++ {
+				out.Pix[xo+c] /= sum
+			}
+		}
+	}
+}
+
+// convolveFloat64 is like convolveFloat, but accumulates the taps in
+// float64 and rounds only the normalized result to float32.
+func convolveFloat64(in, out *floatImage, y0, y1 int, coeffs []float32, index []int, filterLength int) {
+	n := in.Channels
+	acc := make([]float64, n)
+
+	for x := 0; x < out.Rect.Dx(); x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := y0; y < y1; y++ {
+			for c := range acc {
+				acc[c] = 0
+			}
+			var sum float64
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				coeff := float64(coeffs[ci+i])
+				if coeff != 0 {
+					xi := index[ci+i] * n
+					for c := range acc {
+						acc[c] += coeff * float64(row[xi+c])
+					}
+					sum += coeff
+				}
+			}
+			xo := y*out.Stride + x*n
+			for c, v := range acc {
+				out.Pix[xo+c] = float32(v / sum)
+			}
+		}
+	}
+}
+
+// createWeightsInterp returns the kernel weights of interp for dy output
+// samples, moved by shift source pixels. When enlarging, the kernel is
+// widened by the factor 1+upscaleBlur.
+func createWeightsInterp(dy int, interp InterpolationFunction, scale, shift, upscaleBlur float64) ([]float32, []int, int) {
+	taps, kernel := interp.kernel()
+	b := blur
+	if scale < 1 && upscaleBlur > 0 {
+		b = math.Max(blur, (1+upscaleBlur)/scale)
+	}
+	return createWeightsFloat(dy, taps, b, scale, shift, kernel)
+}
+
+// parallelRows splits [0,rows) into contiguous ranges and calls fn
+// for each of them concurrently.
+func parallelRows(rows int, fn func(y0, y1 int)) {
+	parallelRowsN(rows, runtime.GOMAXPROCS(0), fn)
+}
+
+// parallelRowsN is like parallelRows, but uses the given number of
+// goroutines.
+func parallelRowsN(rows, workers int, fn func(y0, y1 int)) {
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		y0, y1 := i*rows/workers, (i+1)*rows/workers
+		go func() {
+			defer wg.Done()
+			fn(y0, y1)
+		}()
+	}
+	wg.Wait()
+}
+
+// newFloatImageFromImage returns the 16-bit premultiplied colors of img as a
+// floatImage with four channels and bounds starting at (0, 0).
+func newFloatImageFromImage(img image.Image) *floatImage {
+	b := img.Bounds()
+	p := newFloatImage(image.Rect(0, 0, b.Dx(), b.Dy()), 4)
+	readFloatRows(img, p, 0, runtime.GOMAXPROCS(0))
+	return p
+}
+
+// readFloatRows fills p, which has four channels and the width of img, with
+// the 16-bit premultiplied colors of the rows of img starting at row y0,
+// counted from the top of img. It uses the given number of goroutines.
+func readFloatRows(img image.Image, p *floatImage, y0, workers int) {
+	b := img.Bounds()
+	parallelRowsN(p.Rect.Dy(), workers, func(r0, r1 int) {
+		for y := r0; y < r1; y++ {
+			i := y * p.Stride
+			for x := 0; x < b.Dx(); x++ {
+				r, g, bl, a := img.At(x+b.Min.X, y+y0+b.Min.Y).RGBA()
+				p.Pix[i+0] = float32(r)
+				p.Pix[i+1] = float32(g)
+				p.Pix[i+2] = float32(bl)
+				p.Pix[i+3] = float32(a)
+				i += 4
+			}
+		}
+	})
+}
+
+// RGBA64 converts a floatImage with four channels of 16-bit premultiplied
+// colors to an *image.RGBA64. Values are rounded and clamped.
+func (p *floatImage) RGBA64() *image.RGBA64 {
+	return p.rgba64(nil)
+}
+
+// rgba64 is like RGBA64, but converts the values with clamp unless it is
+// nil.
+func (p *floatImage) rgba64(clamp func(float32) uint16) *image.RGBA64 {
+	if clamp == nil {
+		clamp = clampFloatUint16
+	}
+	out := image.NewRGBA64(p.Rect)
+	parallelRows(p.Rect.Dy(), func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			i := y * p.Stride
+			o := y * out.Stride
+			for x := 0; x < p.Rect.Dx(); x++ {
+				for c := 0; c < 4; c++ {
+					value := clamp(p.Pix[i+c])
+					out.Pix[o+2*c+0] = uint8(value >> 8)
+					out.Pix[o+2*c+1] = uint8(value)
+				}
+				i += 4
+				o += 8
+			}
+		}
+	})
+	return out
+}
+
+// packRGBA rounds the 16-bit samples of src to 8 bits and stores them in
+// dst, which must be at least as long as src. It is a single loop over
+// contiguous samples without bounds checks or divisions; the division by
+// 0x101 is replaced by a multiplication and a shift, which gives the same
+// result for all 16-bit values.
+func packRGBA(dst []uint8, src []float32) {
+	dst = dst[:len(src)]
+	for i, v := range src {
+		dst[i] = uint8((uint32(clampFloatUint16(v)) + 0x80) * 0xff01 >> 24)
+	}
+}
+
+// clampFloatUint16 rounds x to the nearest value in [0,65535].
+func clampFloatUint16(x float32) uint16 {
+	if x <= 0 {
+		return 0
+	}
+	return floatToUint16(x + 0.5)
+}
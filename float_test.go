@@ -0,0 +1,80 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_FloatResizeIsReproducible(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 97, 61))
+	for y := 0; y < 61; y++ {
+		for x := 0; x < 97; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x * y), uint8(3 * x), uint8(7 * y), 0xff})
+		}
+	}
+	identity := [3][4]float32{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		a := ResizeColorMatrix(40, 33, identity, img, interp).(*image.RGBA64)
+		b := ResizeColorMatrix(40, 33, identity, img, interp).(*image.RGBA64)
+		if !bytes.Equal(a.Pix, b.Pix) {
+			t.Errorf("%v: float results differ between runs", interp)
+		}
+
+		c := Resize(40, 33, img, interp).(*image.RGBA)
+		d := Resize(40, 33, img, interp).(*image.RGBA)
+		if !bytes.Equal(c.Pix, d.Pix) {
+			t.Errorf("%v: results differ between runs", interp)
+		}
+	}
+}
+
+// packRGBAScalar is the straightforward version of packRGBA.
+func packRGBAScalar(dst []uint8, src []float32) {
+	for i := range src {
+		dst[i] = uint8((uint32(clampFloatUint16(src[i])) + 0x80) / 0x101)
+	}
+}
+
+func Test_PackRGBA(t *testing.T) {
+	src := []float32{-70000, -1, -0.5, 0, 0.49, 0.5, 65534.4, 65534.5, 65535, 70000, 1e10}
+	for v := 0; v <= 0xffff; v++ {
+		src = append(src, float32(v), float32(v)+0.25, float32(v)+0.5)
+	}
+	got := make([]uint8, len(src))
+	want := make([]uint8, len(src))
+	packRGBA(got, src)
+	packRGBAScalar(want, src)
+	for i := range src {
+		if got[i] != want[i] {
+			t.Fatalf("%g: want %d, got %d", src[i], want[i], got[i])
+		}
+	}
+}
+
+func benchPack(b *testing.B, pack func([]uint8, []float32)) {
+	src := make([]float32, 4*benchWidth*benchHeight)
+	for i := range src {
+		src[i] = float32(i * 37 % 0x10000)
+	}
+	dst := make([]uint8, len(src))
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pack(dst, src)
+	}
+}
+
+func Benchmark_PackRGBA(b *testing.B) {
+	benchPack(b, packRGBA)
+}
+
+func Benchmark_PackRGBAScalar(b *testing.B) {
+	benchPack(b, packRGBAScalar)
+}
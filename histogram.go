@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+)
+
+// paletteIterations is the number of k-means iterations of
+// ResizeWithPalette.
+const paletteIterations = 8
+
+// ResizeWithHistogram scales an image like ResizePremultRGBA and returns the
+// histograms of the red, green and blue samples of the result, e.g. for
+// media analysis. The histograms count the 8-bit samples as they are
+// stored in the *image.RGBA, i.e. premultiplied, and are collected while
+// the samples are rounded to 8 bits, so the result is not scanned again.
+func ResizeWithHistogram(width, height uint, img image.Image, interp InterpolationFunction) (image.Image, [3][256]uint32) {
+	var hist [3][256]uint32
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	out := newFloatImageFromImage(img)
+	if int(width) != b.Dx() || int(height) != b.Dy() {
+		if b.Dx() <= 0 || b.Dy() <= 0 {
+			return image.NewRGBA(image.Rect(0, 0, 0, 0)), hist
+		}
+		out = resizeFloat(out, int(width), int(height), scaleX, scaleY, interp)
+	}
+
+	result := image.NewRGBA(out.Rect)
+	packRGBAHistogram(result.Pix, out.Pix, &hist)
+	return result, hist
+}
+
+// packRGBAHistogram is like packRGBA for samples with four channels and
+// additionally counts the values of the first three channels in hist.
+func packRGBAHistogram(dst []uint8, src []float32, hist *[3][256]uint32) {
+	dst = dst[:len(src)]
+	for i, v := range src {
+		dst[i] = uint8((uint32(clampFloatUint16(v)) + 0x80) * 0xff01 >> 24)
+		if c := i & 3; c < 3 {
+			hist[c][dst[i]]++
+		}
+	}
+}
+
+// ResizeWithPalette scales an image like Resize and returns up to k dominant
+// colors of the result, most frequent first.
+func ResizeWithPalette(width, height uint, img image.Image, interp InterpolationFunction, k int) (image.Image, []color.RGBA) {
+	m := Resize(width, height, img, interp)
+	b := m.Bounds()
+	if k <= 0 || b.Empty() {
+		return m, nil
+	}
+
+	pixels := make([][4]float64, 0, b.Dx()*b.Dy())
+	var mean [4]float64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(m.At(x, y)).(color.RGBA)
+			p := [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+			pixels = append(pixels, p)
+			for i := range mean {
+				mean[i] += p[i]
+			}
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(pixels))
+	}
+
+	// Farthest point initialization, starting from the mean.
+	centers := [][4]float64{mean}
+	dist := make([]float64, len(pixels))
+	for i, p := range pixels {
+		dist[i] = colorDistance(p, mean)
+	}
+	for len(centers) <= k {
+		far := 0
+		for i, d := range dist {
+			if d > dist[far] {
+				far = i
+			}
+		}
+		if dist[far] == 0 {
+			break
+		}
+		centers = append(centers, pixels[far])
+		for i, p := range pixels {
+			if d := colorDistance(p, pixels[far]); d < dist[i] {
+				dist[i] = d
+			}
+		}
+	}
+	centers = centers[1:]
+	if len(centers) == 0 {
+		// All pixels have the mean color.
+		centers = append(centers, mean)
+	}
+
+	counts := make([]int, len(centers))
+	for iter := 0; iter < paletteIterations; iter++ {
+		sums := make([][4]float64, len(centers))
+		for i := range counts {
+			counts[i] = 0
+		}
+		for _, p := range pixels {
+			nearest := 0
+			for j, c := range centers {
+				if colorDistance(p, c) < colorDistance(p, centers[nearest]) {
+					nearest = j
+				}
+			}
+			for i := range p {
+				sums[nearest][i] += p[i]
+			}
+			counts[nearest]++
+		}
+		for j, n := range counts {
+			if n > 0 {
+				for i := range sums[j] {
+					centers[j][i] = sums[j][i] / float64(n)
+				}
+			}
+		}
+	}
+
+	// Insertion sort by frequency, which keeps the order of ties.
+	for i := 1; i < len(centers); i++ {
+		for j := i; j > 0 && counts[j] > counts[j-1]; j-- {
+			counts[j], counts[j-1] = counts[j-1], counts[j]
+			centers[j], centers[j-1] = centers[j-1], centers[j]
+		}
+	}
+	palette := make([]color.RGBA, len(centers))
+	for i, c := range centers {
+		palette[i] = color.RGBA{uint8(c[0] + 0.5), uint8(c[1] + 0.5), uint8(c[2] + 0.5), uint8(c[3] + 0.5)}
+	}
+	return m, palette
+}
+
+// colorDistance returns the squared euclidean distance of a and b.
+func colorDistance(a, b [4]float64) float64 {
+	var d float64
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
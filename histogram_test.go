@@ -0,0 +1,83 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func Test_ResizeWithHistogram(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 30, 20))
+	draw.Draw(img, img.Rect, image.NewUniform(color.NRGBA{0x20, 0x80, 0xe0, 0xff}), image.ZP, draw.Src)
+
+	m, hist := ResizeWithHistogram(12, 7, img, Lanczos3)
+	if m.Bounds() != image.Rect(0, 0, 12, 7) {
+		t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 12, 7), m.Bounds())
+	}
+	for c, want := range []int{0x20, 0x80, 0xe0} {
+		for v, n := range hist[c] {
+			switch {
+			case v == want && n != 12*7:
+				t.Errorf("channel %d: want %d samples of %#x, got %d", c, 12*7, v, n)
+			case v != want && n != 0:
+				t.Errorf("channel %d: unexpected %d samples of %#x", c, n, v)
+			}
+		}
+	}
+}
+
+func Test_ResizeWithPalette(t *testing.T) {
+	dark, light := color.RGBA{0x20, 0x30, 0x80, 0xff}, color.RGBA{0xf0, 0xc0, 0x10, 0xff}
+	arrangements := map[string]func(x, y int) bool{
+		"halves":       func(x, y int) bool { return x < 32 },
+		"stripes":      func(x, y int) bool { return y/8%2 == 0 },
+		"checkerboard": func(x, y int) bool { return (x/8+y/8)%2 == 0 },
+		"corner":       func(x, y int) bool { return x >= 48 && y >= 48 },
+	}
+	for name, isDark := range arrangements {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				if isDark(x, y) {
+					img.SetRGBA(x, y, dark)
+				} else {
+					img.SetRGBA(x, y, light)
+				}
+			}
+		}
+
+		// Area halves the blocks without blending them.
+		m, palette := ResizeWithPalette(32, 32, img, Area, 2)
+		if m.Bounds() != image.Rect(0, 0, 32, 32) {
+			t.Fatalf("%s: want bounds %v, got %v", name, image.Rect(0, 0, 32, 32), m.Bounds())
+		}
+		if len(palette) != 2 {
+			t.Fatalf("%s: want 2 colors, got %v", name, palette)
+		}
+		for _, want := range []color.RGBA{dark, light} {
+			if !hasColor(palette, want, 8) {
+				t.Errorf("%s: %v not in palette %v", name, want, palette)
+			}
+		}
+	}
+
+	// A uniform image has a single color.
+	uniform := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(uniform, uniform.Rect, image.NewUniform(dark), image.ZP, draw.Src)
+	if _, palette := ResizeWithPalette(5, 5, uniform, Bilinear, 3); len(palette) != 1 || palette[0] != dark {
+		t.Errorf("want palette [%v], got %v", dark, palette)
+	}
+}
+
+// hasColor reports whether palette holds a color whose channels differ by
+// at most tolerance from c.
+func hasColor(palette []color.RGBA, c color.RGBA, tolerance uint32) bool {
+	for _, p := range palette {
+		if absDiff(uint32(p.R), uint32(c.R)) <= tolerance && absDiff(uint32(p.G), uint32(c.G)) <= tolerance &&
+			absDiff(uint32(p.B), uint32(c.B)) <= tolerance && absDiff(uint32(p.A), uint32(c.A)) <= tolerance {
+			return true
+		}
+	}
+	return false
+}
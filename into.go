@@ -0,0 +1,141 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+)
+
+var (
+	// intoPlan is the plan of the last call of ResizeInto, which is reused
+	// while the sizes and the interpolation function stay the same.
+	intoPlanMu sync.Mutex
+	intoPlan   *ResizePlan
+)
+
+// ResizeInto scales img to the size of dst and writes the result into dst,
+// reusing its buffers across calls. It returns ErrNilImage or ErrEmptyImage
+// and leaves dst unchanged if either image is nil or empty.
+func ResizeInto(dst *image.RGBA64, img image.Image, interp InterpolationFunction) error {
+	if dst == nil || img == nil {
+		return ErrNilImage
+	}
+	b, r := img.Bounds(), dst.Bounds()
+	if b.Empty() || r.Empty() {
+		return ErrEmptyImage
+	}
+
+	// Trivial case: copy input image
+	if r.Dx() == b.Dx() && r.Dy() == b.Dy() {
+		draw.Draw(dst, r, img, b.Min, draw.Src)
+		return nil
+	}
+
+	// The converters expect the result at the origin.
+	out := dst
+	if r.Min != image.ZP {
+		view := *dst
+		view.Rect = r.Sub(r.Min)
+		out = &view
+	}
+
+	p := intoPlanFor(b.Dx(), b.Dy(), r.Dx(), r.Dy(), interp)
+	temp := getTempRGBA64(b.Dy(), r.Dx())
+	defer putTempRGBA64(temp)
+
+	cpus := p.parallelism()
+	wg := sync.WaitGroup{}
+
+	if interp == NearestNeighbor {
+		// horizontal filter, results in transposed temporary image
+		coeffs, offset, filterLength := p.weightsNearestX()
+		wg.Add(cpus)
+		for i := 0; i < cpus; i++ {
+			slice := makeSlice(temp, i, cpus).(*image.RGBA64)
+			go func() {
+				defer wg.Done()
+				if rgba64, ok := img.(*image.RGBA64); ok {
+					nearestRGBA64(rgba64, slice, p.scaleX, coeffs, offset, filterLength)
+				} else {
+					nearestGeneric(img, slice, p.scaleX, coeffs, offset, filterLength)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// horizontal filter on transposed image, result is not transposed
+		coeffs, offset, filterLength = p.weightsNearestY()
+		wg.Add(cpus)
+		for i := 0; i < cpus; i++ {
+			slice := makeSlice(out, i, cpus).(*image.RGBA64)
+			go func() {
+				defer wg.Done()
+				nearestRGBA64(temp, slice, p.scaleY, coeffs, offset, filterLength)
+			}()
+		}
+		wg.Wait()
+		return nil
+	}
+
+	// horizontal filter, results in transposed temporary image
+	coeffs, offset, filterLength := p.weights16X()
+	wg.Add(cpus)
+	for i := 0; i < cpus; i++ {
+		slice := makeSlice(temp, i, cpus).(*image.RGBA64)
+		go func() {
+			defer wg.Done()
+			switch input := img.(type) {
+			case *image.RGBA64:
+				resizeRGBA64(input, slice, p.scaleX, coeffs, offset, filterLength)
+			case *image.NRGBA64:
+				resizeNRGBA64(input, slice, p.scaleX, coeffs, offset, filterLength)
+			default:
+				resizeGeneric(img, slice, p.scaleX, coeffs, offset, filterLength)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// horizontal filter on transposed image, result is not transposed
+	coeffs, offset, filterLength = p.weights16Y()
+	wg.Add(cpus)
+	for i := 0; i < cpus; i++ {
+		slice := makeSlice(out, i, cpus).(*image.RGBA64)
+		go func() {
+			defer wg.Done()
+			resizeRGBA64(temp, slice, p.scaleY, coeffs, offset, filterLength)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// intoPlanFor returns the plan of ResizeInto for the given sizes. The plan
+// of the previous call is reused if it matches.
+func intoPlanFor(srcWidth, srcHeight, width, height int, interp InterpolationFunction) *ResizePlan {
+	intoPlanMu.Lock()
+	defer intoPlanMu.Unlock()
+	if p := intoPlan; p != nil && p.srcWidth == srcWidth && p.srcHeight == srcHeight &&
+		p.width == width && p.height == height && p.interp == interp {
+		return p
+	}
+	scaleX, scaleY := calcFactors(uint(width), uint(height), float64(srcWidth), float64(srcHeight))
+	intoPlan = newPlan(srcWidth, srcHeight, width, height, scaleX, scaleY, interp)
+	return intoPlan
+}
@@ -0,0 +1,74 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeInto(t *testing.T) {
+	rgba64 := image.NewRGBA64(image.Rect(0, 0, 90, 70))
+	for i := range rgba64.Pix {
+		rgba64.Pix[i] = uint8(i * 7)
+	}
+	nrgba64 := image.NewNRGBA64(image.Rect(5, 5, 65, 105))
+	for i := range nrgba64.Pix {
+		nrgba64.Pix[i] = uint8(i * 13)
+	}
+	alpha16 := image.NewAlpha16(image.Rect(0, 0, 50, 40))
+	for i := range alpha16.Pix {
+		alpha16.Pix[i] = uint8(i * 5)
+	}
+
+	for _, img := range []image.Image{rgba64, nrgba64, alpha16} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+			for _, r := range []image.Rectangle{image.Rect(0, 0, 30, 20), image.Rect(10, 20, 140, 170)} {
+				dst := image.NewRGBA64(r)
+				if err := ResizeInto(dst, img, interp); err != nil {
+					t.Fatal(err)
+				}
+				want := Resize(uint(r.Dx()), uint(r.Dy()), img, interp)
+				if _, ok := want.(*image.RGBA64); !ok {
+					// e.g. NRGBA64 images, which NearestNeighbor keeps straight
+					continue
+				}
+				wb := want.Bounds()
+				for y := 0; y < r.Dy(); y++ {
+					for x := 0; x < r.Dx(); x++ {
+						g := dst.At(r.Min.X+x, r.Min.Y+y)
+						w := color.RGBA64Model.Convert(want.At(wb.Min.X+x, wb.Min.Y+y))
+						if g != w {
+							t.Fatalf("%T %v interp %v: pixel (%d,%d): want %v, got %v", img, r, interp, x, y, w, g)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if err := ResizeInto(image.NewRGBA64(image.Rect(0, 0, 0, 10)), rgba64, Bilinear); err != ErrEmptyImage {
+		t.Errorf("empty destination: want %v, got %v", ErrEmptyImage, err)
+	}
+	if err := ResizeInto(nil, rgba64, Bilinear); err != ErrNilImage {
+		t.Errorf("nil destination: want %v, got %v", ErrNilImage, err)
+	}
+}
+
+func Benchmark_ResizeInto(b *testing.B) {
+	img := image.NewRGBA64(image.Rect(0, 0, 640, 480))
+	dst := image.NewRGBA64(image.Rect(0, 0, 320, 240))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ResizeInto(dst, img, Lanczos3)
+	}
+}
+
+func Benchmark_ResizeRGBA64Frames(b *testing.B) {
+	img := image.NewRGBA64(image.Rect(0, 0, 640, 480))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Resize(320, 240, img, Lanczos3)
+	}
+}
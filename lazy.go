@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+)
+
+// lazyImage is the result of LazyResize. Its pixels are computed by At.
+type lazyImage struct {
+	src    image.Image
+	rect   image.Rectangle
+	coeffs [2][]float32
+	index  [2][]int
+	length [2]int
+}
+
+// LazyResize returns img scaled like Resize as an image whose pixels are
+// computed when they are read. img must not be modified while the result is
+// used.
+func LazyResize(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	p := &lazyImage{src: img, rect: image.Rect(0, 0, int(width), int(height))}
+	var offset []int
+	p.coeffs[0], offset, p.length[0] = createWeightsInterp(int(width), interp, scaleX, 0, 0)
+	p.index[0] = borderIndex(p.coeffs[0], offset, p.length[0], b.Dx(), Replicate)
+	p.coeffs[1], offset, p.length[1] = createWeightsInterp(int(height), interp, scaleY, 0, 0)
+	p.index[1] = borderIndex(p.coeffs[1], offset, p.length[1], b.Dy(), Replicate)
+	return p
+}
+
+func (p *lazyImage) ColorModel() color.Model { return color.RGBA64Model }
+
+func (p *lazyImage) Bounds() image.Rectangle { return p.rect }
+
+func (p *lazyImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(p.rect)) {
+		return color.RGBA64{}
+	}
+	b := p.src.Bounds()
+	cx, ix := p.coeffs[0][x*p.length[0]:(x+1)*p.length[0]], p.index[0][x*p.length[0]:]
+	cy, iy := p.coeffs[1][y*p.length[1]:(y+1)*p.length[1]], p.index[1][y*p.length[1]:]
+
+	var rgba [4]float32
+	var sum float32
+	for j, wy := range cy {
+		if wy == 0 {
+			continue
+		}
+		// The horizontal filter, clamped like the temporary image of
+		// Resize.
+		var row [4]float32
+		var rowSum float32
+		for i, wx := range cx {
+			if wx == 0 {
+				continue
+			}
+			r, g, bl, a := p.src.At(b.Min.X+ix[i], b.Min.Y+iy[j]).RGBA()
+			row[0] += wx * float32(r)
+			row[1] += wx * float32(g)
+			row[2] += wx * float32(bl)
+			row[3] += wx * float32(a)
+			rowSum += wx
+		}
+		for c, v := range row {
+			v /= rowSum
+			switch {
+			case v < 0:
+				v = 0
+			case v > 0xffff:
+				v = 0xffff
+			}
+			rgba[c] += wy * v
+		}
+		sum += wy
+	}
+	return color.RGBA64{
+		clampFloatUint16(rgba[0] / sum),
+		clampFloatUint16(rgba[1] / sum),
+		clampFloatUint16(rgba[2] / sum),
+		clampFloatUint16(rgba[3] / sum),
+	}
+}
@@ -0,0 +1,56 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_LazyResize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 20, 90, 80))
+	for y := 20; y < 80; y++ {
+		for x := 10; x < 90; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(3 * x), uint8(2 * y), uint8(x + y), 0xff})
+		}
+	}
+
+	points := []image.Point{{0, 0}, {5, 7}, {29, 0}, {0, 39}, {29, 39}, {17, 22}}
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+		for _, size := range []image.Point{{30, 40}, {200, 150}} {
+			lazy := LazyResize(uint(size.X), uint(size.Y), img, interp)
+			eager := Resize(uint(size.X), uint(size.Y), img, interp)
+			if lazy.Bounds() != eager.Bounds() {
+				t.Fatalf("want bounds %v, got %v", eager.Bounds(), lazy.Bounds())
+			}
+			for _, pt := range points {
+				r0, g0, b0, a0 := lazy.At(pt.X, pt.Y).RGBA()
+				r1, g1, b1, a1 := eager.At(pt.X, pt.Y).RGBA()
+				if absDiff(r0>>8, r1>>8) > 1 || absDiff(g0>>8, g1>>8) > 1 || absDiff(b0>>8, b1>>8) > 1 || a0>>8 != a1>>8 {
+					t.Errorf("%v %v %v: want %v, got %v", interp, size, pt, eager.At(pt.X, pt.Y), lazy.At(pt.X, pt.Y))
+				}
+			}
+		}
+	}
+
+	// Lanczos3 overshoots at the edges of a checkerboard. Resize clamps
+	// the horizontal pass, so the overshoot does not add up.
+	checker := image.NewGray16(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if (x/2+y/2)%2 == 0 {
+				checker.SetGray16(x, y, color.Gray16{0xffff})
+			}
+		}
+	}
+	lazy := LazyResize(50, 50, checker, Lanczos3)
+	eager := Resize(50, 50, checker, Lanczos3)
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 50; x++ {
+			v0, _, _, _ := lazy.At(x, y).RGBA()
+			v1, _, _, _ := eager.At(x, y).RGBA()
+			if absDiff(v0, v1) > 0x100 {
+				t.Fatalf("checkerboard (%d,%d): want %d, got %d", x, y, v1, v0)
+			}
+		}
+	}
+}
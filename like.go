@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+)
+
+// ResizeLike scales img to the size of ref using the interpolation function
+// interp. The bounds of the returned image are equal to the bounds of ref,
+// including its origin.
+// If ref has no pixels, img is returned unchanged.
+func ResizeLike(ref image.Image, img image.Image, interp InterpolationFunction) image.Image {
+	r := ref.Bounds()
+	if r.Empty() {
+		return img
+	}
+	if img.Bounds() == r {
+		return img
+	}
+	m := Resize(uint(r.Dx()), uint(r.Dy()), img, interp)
+	return translate(m, r.Min)
+}
+
+// ResizeAs scales an image like Resize, but returns an image of the same type
+// as img where it can be allocated.
+func ResizeAs(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	if p, ok := img.(*image.Paletted); ok {
+		return ResizePalettedSamePalette(width, height, p, interp)
+	}
+	m := Resize(width, height, img, interp)
+	if reflect.TypeOf(m) == reflect.TypeOf(img) {
+		return m
+	}
+
+	var out draw.Image
+	switch img.(type) {
+	case *image.NRGBA:
+		out = image.NewNRGBA(m.Bounds())
+	case *image.NRGBA64:
+		out = image.NewNRGBA64(m.Bounds())
+	case *image.CMYK:
+		out = image.NewCMYK(m.Bounds())
+	default:
+		return m
+	}
+	draw.Draw(out, out.Bounds(), m, m.Bounds().Min, draw.Src)
+	return out
+}
+
+// translate returns an image with the pixels of img whose bounds start at min.
+// Pixels are shared with img.
+func translate(img image.Image, min image.Point) image.Image {
+	d := min.Sub(img.Bounds().Min)
+	if d == (image.Point{}) {
+		return img
+	}
+	switch m := img.(type) {
+	case *image.RGBA:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	case *image.NRGBA:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	case *image.RGBA64:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	case *image.NRGBA64:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	case *image.Gray:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	case *image.Gray16:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	case *image.YCbCr:
+		c := *m
+		c.Rect = c.Rect.Add(d)
+		return &c
+	}
+	return &translatedImage{img, d}
+}
+
+// translatedImage is a view of an image whose coordinates are shifted by d.
+type translatedImage struct {
+	image.Image
+	d image.Point
+}
+
+func (p *translatedImage) Bounds() image.Rectangle {
+	return p.Image.Bounds().Add(p.d)
+}
+
+func (p *translatedImage) At(x, y int) color.Color {
+	return p.Image.At(x-p.d.X, y-p.d.Y)
+}
@@ -0,0 +1,87 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func Test_ResizeLike(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	src.SetRGBA(0, 0, color.RGBA{0xff, 0, 0, 0xff})
+
+	refs := []image.Image{
+		image.NewGray(image.Rect(0, 0, 20, 10)),
+		image.NewGray(image.Rect(5, 7, 25, 17)),
+		image.NewGray(image.Rect(-3, -4, 37, 26)),
+		image.NewGray(image.Rect(10, 10, 50, 40)),
+	}
+	for _, ref := range refs {
+		m := ResizeLike(ref, src, Bilinear)
+		if m.Bounds() != ref.Bounds() {
+			t.Errorf("want bounds %v, got %v", ref.Bounds(), m.Bounds())
+			continue
+		}
+		min := ref.Bounds().Min
+		if r, _, _, _ := m.At(min.X, min.Y).RGBA(); r == 0 {
+			t.Errorf("%v: top left pixel lost", ref.Bounds())
+		}
+	}
+
+	if src.Rect != image.Rect(0, 0, 40, 30) {
+		t.Errorf("source bounds modified: %v", src.Rect)
+	}
+}
+
+func Test_ResizeLikeGeneric(t *testing.T) {
+	src := image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.Black, color.White})
+	ref := image.NewGray(image.Rect(2, 3, 6, 7))
+
+	m := ResizeLike(ref, src, Bilinear)
+	if m.Bounds() != ref.Bounds() {
+		t.Errorf("want bounds %v, got %v", ref.Bounds(), m.Bounds())
+	}
+}
+
+func Test_ResizeAs(t *testing.T) {
+	r := image.Rect(0, 0, 40, 30)
+	rgba := image.NewRGBA(r)
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+		if i%4 == 3 {
+			rgba.Pix[i] = 0xff
+		}
+	}
+	pal := image.NewPaletted(r, color.Palette{color.Black, color.White})
+	for i := range pal.Pix {
+		pal.Pix[i] = uint8(i % 2)
+	}
+	imgs := []image.Image{rgba, image.NewNRGBA(r), image.NewRGBA64(r), image.NewNRGBA64(r),
+		image.NewGray(r), image.NewGray16(r), image.NewAlpha(r), image.NewAlpha16(r),
+		image.NewCMYK(r), image.NewYCbCr(r, image.YCbCrSubsampleRatio420), pal}
+	for _, img := range imgs[1:] {
+		if d, ok := img.(draw.Image); ok && img != pal {
+			draw.Draw(d, r, rgba, image.ZP, draw.Src)
+		}
+	}
+
+	for _, img := range imgs {
+		m := ResizeAs(20, 15, img, Bilinear)
+		if reflect.TypeOf(m) != reflect.TypeOf(img) {
+			t.Errorf("%T: got %T", img, m)
+			continue
+		}
+		if m.Bounds() != image.Rect(0, 0, 20, 15) {
+			t.Errorf("%T: want bounds %v, got %v", img, image.Rect(0, 0, 20, 15), m.Bounds())
+		}
+		if img == pal {
+			continue
+		}
+		want := m.ColorModel().Convert(Resize(20, 15, img, Bilinear).At(7, 5))
+		if got := m.At(7, 5); got != want {
+			t.Errorf("%T: want %v, got %v", img, want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// maskedMinSupport is the least share of the kernel weight that has to fall
+// on valid cells for an output cell of ResizeMasked2 to be valid.
+const maskedMinSupport = 0.5
+
+// ResizeMasked2 scales an image and its mask, excluding cells whose mask is 0
+// from the interpolation. The data is an *image.RGBA64.
+func ResizeMasked2(width, height uint, img image.Image, mask *image.Alpha, interp InterpolationFunction) (image.Image, *image.Alpha) {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img, mask
+	}
+
+	mb := mask.Bounds()
+	in := weightedFloatImage(img, func(x, y int) float32 {
+		p := image.Pt(x+mb.Min.X, y+mb.Min.Y)
+		if !p.In(mb) || mask.Pix[mask.PixOffset(p.X, p.Y)] == 0 {
+			return 0
+		}
+		return 1
+	})
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+
+	resultMask := image.NewAlpha(out.Rect)
+	for y := 0; y < out.Rect.Dy(); y++ {
+		for x := 0; x < out.Rect.Dx(); x++ {
+			if out.Pix[out.PixOffset(x, y)+4] >= maskedMinSupport {
+				resultMask.Pix[resultMask.PixOffset(x, y)] = 0xff
+			}
+		}
+	}
+	return normalizeWeighted(out, maskedMinSupport).RGBA64(), resultMask
+}
@@ -0,0 +1,53 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeMasked2(t *testing.T) {
+	// A gray field with a masked 8x8 hole, which contains garbage.
+	img := image.NewGray16(image.Rect(0, 0, 32, 32))
+	mask := image.NewAlpha(img.Bounds())
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if x >= 12 && x < 20 && y >= 12 && y < 20 {
+				img.SetGray16(x, y, color.Gray16{0xffff})
+			} else {
+				img.SetGray16(x, y, color.Gray16{0x4000})
+				mask.SetAlpha(x, y, color.Alpha{0xff})
+			}
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m, mm := ResizeMasked2(16, 16, img, mask, interp)
+		if mm.Bounds() != image.Rect(0, 0, 16, 16) {
+			t.Fatalf("want mask bounds %v, got %v", image.Rect(0, 0, 16, 16), mm.Bounds())
+		}
+		// The center of the hole stays masked.
+		for y := 7; y < 9; y++ {
+			for x := 7; x < 9; x++ {
+				if a := mm.AlphaAt(x, y).A; a != 0 {
+					t.Errorf("interp %v: cell (%d,%d) is not masked", interp, x, y)
+				}
+			}
+		}
+		// Valid cells never see the garbage in the hole.
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				if mm.AlphaAt(x, y).A == 0 {
+					continue
+				}
+				if r, _, _, _ := m.At(x, y).RGBA(); absDiff(r, 0x4000) > 0x10 {
+					t.Errorf("interp %v: cell (%d,%d): want %#x, got %#x", interp, x, y, 0x4000, r)
+				}
+			}
+		}
+		// Cells far from the hole are valid.
+		if mm.AlphaAt(0, 0).A == 0 || mm.AlphaAt(15, 15).A == 0 {
+			t.Errorf("interp %v: cells outside of the hole are masked", interp)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// GenerateMipmaps returns img followed by its levels, each half the size of
+// the previous one, down to 1 x 1 pixels.
+func GenerateMipmaps(img image.Image) []image.Image {
+	b := img.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return []image.Image{img}
+	}
+
+	levels := []image.Image{img}
+	// Levels are computed from the previous level in linear light, so they
+	// are not quantized to 8 bits more than once.
+	prev := linearFloatImage(img)
+	for prev.Rect.Dx() > 1 || prev.Rect.Dy() > 1 {
+		width, height := halfSize(prev.Rect.Dx()), halfSize(prev.Rect.Dy())
+		scaleX := float64(prev.Rect.Dx()) / float64(width)
+		scaleY := float64(prev.Rect.Dy()) / float64(height)
+		prev = resizeFloat(prev, width, height, scaleX, scaleY, Bilinear)
+		levels = append(levels, prev.linearNRGBA())
+	}
+	return levels
+}
+
+// halfSize returns the size of the next mipmap level.
+func halfSize(size int) int {
+	if size > 1 {
+		return size / 2
+	}
+	return 1
+}
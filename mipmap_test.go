@@ -0,0 +1,41 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_GenerateMipmaps(t *testing.T) {
+	checker := image.NewGray(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if (x+y)%2 == 0 {
+				checker.SetGray(x, y, color.Gray{0xff})
+			}
+		}
+	}
+
+	levels := GenerateMipmaps(checker)
+	if len(levels) != 5 {
+		t.Fatalf("want 5 levels, got %d", len(levels))
+	}
+	for i, m := range levels {
+		if want := 16 >> uint(i); m.Bounds() != image.Rect(0, 0, want, want) {
+			t.Errorf("level %d: want %dx%d, got %v", i, want, want, m.Bounds())
+		}
+	}
+	// Half the light of white is much brighter than 128 in sRGB.
+	c := color.NRGBAModel.Convert(levels[4].At(0, 0)).(color.NRGBA)
+	if c.R < 186 || c.R > 189 || c.A != 0xff {
+		t.Errorf("want about 188, got %v", c)
+	}
+
+	levels = GenerateMipmaps(image.NewRGBA(image.Rect(0, 0, 37, 5)))
+	if len(levels) != 6 {
+		t.Fatalf("want 6 levels, got %d", len(levels))
+	}
+	if b := levels[5].Bounds(); b != image.Rect(0, 0, 1, 1) {
+		t.Errorf("last level: want 1x1, got %v", b)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeMultiChannel scales channels float channels per pixel read with get
+// and written with set. Values are neither clamped nor quantized.
+func ResizeMultiChannel(srcWidth, srcHeight, width, height, channels int, get func(x, y, c int) float32, set func(x, y, c int, v float32), interp InterpolationFunction) {
+	if srcWidth <= 0 || srcHeight <= 0 || channels <= 0 {
+		return
+	}
+	w, h, scaleX, scaleY := scaledSize(uint(width), uint(height), srcWidth, srcHeight)
+	width, height = int(w), int(h)
+
+	in := newFloatImage(image.Rect(0, 0, srcWidth, srcHeight), channels)
+	for y := 0; y < srcHeight; y++ {
+		for x := 0; x < srcWidth; x++ {
+			i := in.PixOffset(x, y)
+			for c := 0; c < channels; c++ {
+				in.Pix[i+c] = get(x, y, c)
+			}
+		}
+	}
+
+	out := in
+	if width != srcWidth || height != srcHeight {
+		out = resizeFloat(in, width, height, scaleX, scaleY, interp)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := out.PixOffset(x, y)
+			for c := 0; c < channels; c++ {
+				set(x, y, c, out.Pix[i+c])
+			}
+		}
+	}
+}
+
+// ResizeFloat scales interleaved float samples stored row by row in pix and
+// returns them in the same layout together with their size.
+func ResizeFloat(width, height uint, pix []float32, srcWidth, srcHeight, channels int, interp InterpolationFunction) ([]float32, int, int) {
+	if srcWidth <= 0 || srcHeight <= 0 || channels <= 0 {
+		return pix, srcWidth, srcHeight
+	}
+	width, height, scaleX, scaleY := scaledSize(width, height, srcWidth, srcHeight)
+
+	// Trivial case: return input data
+	if int(width) == srcWidth && int(height) == srcHeight {
+		return pix, srcWidth, srcHeight
+	}
+
+	in := &floatImage{
+		Pix:      pix[:srcWidth*srcHeight*channels],
+		Stride:   srcWidth * channels,
+		Rect:     image.Rect(0, 0, srcWidth, srcHeight),
+		Channels: channels,
+	}
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+	return out.Pix, int(width), int(height)
+}
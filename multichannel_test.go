@@ -0,0 +1,76 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_ResizeMultiChannel(t *testing.T) {
+	const bands = 6
+	src := make([][bands]float32, 40*30)
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			for c := 0; c < bands; c++ {
+				src[y*40+x][c] = float32((c+1)*x + 100*c*y)
+			}
+		}
+	}
+	dst := make([][bands]float32, 16*12)
+	get := func(x, y, c int) float32 { return src[y*40+x][c] }
+	set := func(x, y, c int, v float32) { dst[y*16+x][c] = v }
+	ResizeMultiChannel(40, 30, 16, 0, bands, get, set, Lanczos3)
+
+	// Every band matches a single channel resize of that band, up to the
+	// quantization of the 16-bit path.
+	for c := 0; c < bands; c++ {
+		band := image.NewGray16(image.Rect(0, 0, 40, 30))
+		for y := 0; y < 30; y++ {
+			for x := 0; x < 40; x++ {
+				band.SetGray16(x, y, color.Gray16{uint16(src[y*40+x][c])})
+			}
+		}
+		want := Resize(16, 0, band, Lanczos3).(*image.Gray16)
+		for y := 0; y < 12; y++ {
+			for x := 0; x < 16; x++ {
+				if d := math.Abs(float64(dst[y*16+x][c]) - float64(want.Gray16At(x, y).Y)); d > 2 {
+					t.Fatalf("band %d (%d,%d): want %d, got %g", c, x, y, want.Gray16At(x, y).Y, dst[y*16+x][c])
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeFloat(t *testing.T) {
+	// An HDR highlight of 4 on a background of 0.5, in three channels.
+	pix := make([]float32, 3*20*20)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			v := float32(0.5)
+			if x >= 8 && x < 12 && y >= 8 && y < 12 {
+				v = 4
+			}
+			for c := 0; c < 3; c++ {
+				pix[3*(20*y+x)+c] = v
+			}
+		}
+	}
+
+	out, w, h := ResizeFloat(10, 0, pix, 20, 20, 3, Area)
+	if w != 10 || h != 10 || len(out) != 3*10*10 {
+		t.Fatalf("want 10x10 pixels, got %dx%d and %d samples", w, h, len(out))
+	}
+	for c := 0; c < 3; c++ {
+		if v := out[3*(10*4+4)+c]; v != 4 {
+			t.Errorf("channel %d: highlight clamped to %v", c, v)
+		}
+		if v := out[3*(10*0+0)+c]; v != 0.5 {
+			t.Errorf("channel %d: background changed to %v", c, v)
+		}
+	}
+
+	if _, w, h := ResizeFloat(20, 20, pix, 20, 20, 3, Area); w != 20 || h != 20 {
+		t.Errorf("same size: got %dx%d", w, h)
+	}
+}
@@ -28,7 +28,8 @@ func floatToUint8(x float32) uint8 {
 }
 
 func floatToUint16(x float32) uint16 {
-	if x > 0xfffe {
+	// Values up to 0xffff convert without overflow.
+	if x >= 0xffff {
 		return 0xffff
 	}
 	return uint16(x)
@@ -63,57 +64,98 @@ func nearestGeneric(in image.Image, out *image.RGBA64, scale float64, coeffs []b
 			}
 
 			offset := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
-			value := floatToUint16(rgba[0] / sum)
+			value := floatToUint16(rgba[0]/sum + 0.5)
 			out.Pix[offset+0] = uint8(value >> 8)
 			out.Pix[offset+1] = uint8(value)
-			value = floatToUint16(rgba[1] / sum)
+			value = floatToUint16(rgba[1]/sum + 0.5)
 			out.Pix[offset+2] = uint8(value >> 8)
 			out.Pix[offset+3] = uint8(value)
-			value = floatToUint16(rgba[2] / sum)
+			value = floatToUint16(rgba[2]/sum + 0.5)
 			out.Pix[offset+4] = uint8(value >> 8)
 			out.Pix[offset+5] = uint8(value)
-			value = floatToUint16(rgba[3] / sum)
+			value = floatToUint16(rgba[3]/sum + 0.5)
 			out.Pix[offset+6] = uint8(value >> 8)
 			out.Pix[offset+7] = uint8(value)
 		}
 	}
 }
 
-func nearestRGBA(in *image.RGBA, out *image.RGBA, scale float64, coeffs []bool, offset []int, filterLength int) {
+// averageUint8 returns floatToUint8(float32(x) / float32(n)) for a sum x of
+// n 8-bit values using integer arithmetic only.
+func averageUint8(x, n uint32) uint8 {
+	if x > 0xfe*n {
+		return 0xff
+	}
+	return uint8(x / n)
+}
+
+// nearestTaps returns the source indices of the selected taps of coeffs,
+// clamped to [0,max], so that idx[first[i]:first[i+1]] are those of result
+// index i.
+func nearestTaps(coeffs []bool, offset []int, filterLength, max int) (idx, first []int) {
+	n := len(offset)
+	first = make([]int, n+1)
+	for y := 0; y < n; y++ {
+		first[y] = len(idx)
+		for i := 0; i < filterLength; i++ {
+			if coeffs[y*filterLength+i] {
+				xi := offset[y] + i
+				switch {
+				case xi < 0:
+					xi = 0
+				case xi >= max:
+					xi = max
+				}
+				idx = append(idx, xi)
+			}
+		}
+	}
+	first[n] = len(idx)
+	return idx, first
+}
+
+// nearestRGBA scales in in a single pass, without a transposed temporary
+// image. Every result pixel averages the selected taps of each of its
+// source rows and then the rows, rounding like the two passes of the other
+// nearest-neighbor converters, so the result is the same.
+func nearestRGBA(in *image.RGBA, out *image.RGBA, xIdx, xFirst, yIdx, yFirst []int) {
 	newBounds := out.Bounds()
-	maxX := in.Bounds().Dx() - 1
 
-	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
-		row := in.Pix[x*in.Stride:]
-		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
-			var rgba [4]float32
-			var sum float32
-			start := offset[y]
-			ci := y * filterLength
-			for i := 0; i < filterLength; i++ {
-				if coeffs[ci+i] {
-					xi := start + i
-					switch {
-					case uint(xi) < uint(maxX):
-						xi *= 4
-					case xi >= maxX:
-						xi = 4 * maxX
-					default:
-						xi = 0
-					}
-					rgba[0] += float32(row[xi+0])
-					rgba[1] += float32(row[xi+1])
-					rgba[2] += float32(row[xi+2])
-					rgba[3] += float32(row[xi+3])
-					sum++
+	for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+		ys := yIdx[yFirst[y]:yFirst[y+1]]
+		xo := (y - newBounds.Min.Y) * out.Stride
+		for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+			xs := xIdx[xFirst[x]:xFirst[x+1]]
+			if len(xs) == 1 && len(ys) == 1 {
+				// Upscaling: copy the source pixel.
+				copy(out.Pix[xo:xo+4], in.Pix[ys[0]*in.Stride+4*xs[0]:])
+				xo += 4
+				continue
+			}
+
+			var rgba [4]uint32
+			for _, yi := range ys {
+				row := in.Pix[yi*in.Stride:]
+				var sum [4]uint32
+				for _, xi := range xs {
+					sum[0] += uint32(row[4*xi+0])
+					sum[1] += uint32(row[4*xi+1])
+					sum[2] += uint32(row[4*xi+2])
+					sum[3] += uint32(row[4*xi+3])
 				}
+				n := uint32(len(xs))
+				rgba[0] += uint32(averageUint8(sum[0], n))
+				rgba[1] += uint32(averageUint8(sum[1], n))
+				rgba[2] += uint32(averageUint8(sum[2], n))
+				rgba[3] += uint32(averageUint8(sum[3], n))
 			}
 
-			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
-			out.Pix[xo+0] = floatToUint8(rgba[0] / sum)
-			out.Pix[xo+1] = floatToUint8(rgba[1] / sum)
-			out.Pix[xo+2] = floatToUint8(rgba[2] / sum)
-			out.Pix[xo+3] = floatToUint8(rgba[3] / sum)
+			n := uint32(len(ys))
+			out.Pix[xo+0] = averageUint8(rgba[0], n)
+			out.Pix[xo+1] = averageUint8(rgba[1], n)
+			out.Pix[xo+2] = averageUint8(rgba[2], n)
+			out.Pix[xo+3] = averageUint8(rgba[3], n)
+			xo += 4
 		}
 	}
 }
@@ -188,16 +230,16 @@ func nearestRGBA64(in *image.RGBA64, out *image.RGBA64, scale float64, coeffs []
 			}
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
-			value := floatToUint16(rgba[0] / sum)
+			value := floatToUint16(rgba[0]/sum + 0.5)
 			out.Pix[xo+0] = uint8(value >> 8)
 			out.Pix[xo+1] = uint8(value)
-			value = floatToUint16(rgba[1] / sum)
+			value = floatToUint16(rgba[1]/sum + 0.5)
 			out.Pix[xo+2] = uint8(value >> 8)
 			out.Pix[xo+3] = uint8(value)
-			value = floatToUint16(rgba[2] / sum)
+			value = floatToUint16(rgba[2]/sum + 0.5)
 			out.Pix[xo+4] = uint8(value >> 8)
 			out.Pix[xo+5] = uint8(value)
-			value = floatToUint16(rgba[3] / sum)
+			value = floatToUint16(rgba[3]/sum + 0.5)
 			out.Pix[xo+6] = uint8(value >> 8)
 			out.Pix[xo+7] = uint8(value)
 		}
@@ -235,16 +277,16 @@ func nearestNRGBA64(in *image.NRGBA64, out *image.NRGBA64, scale float64, coeffs
 			}
 
 			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*8
-			value := floatToUint16(rgba[0] / sum)
+			value := floatToUint16(rgba[0]/sum + 0.5)
 			out.Pix[xo+0] = uint8(value >> 8)
 			out.Pix[xo+1] = uint8(value)
-			value = floatToUint16(rgba[1] / sum)
+			value = floatToUint16(rgba[1]/sum + 0.5)
 			out.Pix[xo+2] = uint8(value >> 8)
 			out.Pix[xo+3] = uint8(value)
-			value = floatToUint16(rgba[2] / sum)
+			value = floatToUint16(rgba[2]/sum + 0.5)
 			out.Pix[xo+4] = uint8(value >> 8)
 			out.Pix[xo+5] = uint8(value)
-			value = floatToUint16(rgba[3] / sum)
+			value = floatToUint16(rgba[3]/sum + 0.5)
 			out.Pix[xo+6] = uint8(value >> 8)
 			out.Pix[xo+7] = uint8(value)
 		}
@@ -310,7 +352,7 @@ func nearestGray16(in *image.Gray16, out *image.Gray16, scale float64, coeffs []
 			}
 
 			offset := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*2
-			value := floatToUint16(gray / sum)
+			value := floatToUint16(gray/sum + 0.5)
 			out.Pix[offset+0] = uint8(value >> 8)
 			out.Pix[offset+1] = uint8(value)
 		}
@@ -16,7 +16,10 @@ THIS SOFTWARE.
 
 package resize
 
-import "testing"
+import (
+	"image"
+	"testing"
+)
 
 func Test_FloatToUint8(t *testing.T) {
 	var testData = []struct {
@@ -47,11 +50,117 @@ func Test_FloatToUint16(t *testing.T) {
 		{128, 128},
 		{1, 1},
 		{65536, 65535},
+		{65534, 65534},
+		{65534.4, 65534},
+		{65534.9, 65534},
+		{65535.0, 65535},
+		{1e9, 65535},
 	}
 	for _, test := range testData {
 		actual := floatToUint16(test.in)
 		if actual != test.expected {
-			t.Fail()
+			t.Errorf("floatToUint16(%v) = %d, want %d", test.in, actual, test.expected)
+		}
+	}
+}
+
+func Test_ClampFloatUint16(t *testing.T) {
+	var testData = []struct {
+		in       float32
+		expected uint16
+	}{
+		{-1, 0},
+		{0, 0},
+		{0.4, 0},
+		{65534.4, 65534},
+		{65534.6, 65535},
+		{65535.0, 65535},
+		{70000, 65535},
+	}
+	for _, test := range testData {
+		actual := clampFloatUint16(test.in)
+		if actual != test.expected {
+			t.Errorf("clampFloatUint16(%v) = %d, want %d", test.in, actual, test.expected)
+		}
+	}
+}
+
+func Test_AverageUint8(t *testing.T) {
+	for n := uint32(1); n <= 64; n++ {
+		for x := uint32(0); x <= 0xff*n; x++ {
+			if a, f := averageUint8(x, n), floatToUint8(float32(x)/float32(n)); a != f {
+				t.Fatalf("%d/%d: want %d, got %d", x, n, f, a)
+			}
+		}
+	}
+}
+
+// nearestRGBATwoPass is the converter of the two passes that nearestRGBA
+// replaces.
+func nearestRGBATwoPass(in *image.RGBA, out *image.RGBA, scale float64, coeffs []bool, offset []int, filterLength int) {
+	newBounds := out.Bounds()
+	maxX := in.Bounds().Dx() - 1
+
+	for x := newBounds.Min.X; x < newBounds.Max.X; x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := newBounds.Min.Y; y < newBounds.Max.Y; y++ {
+			var rgba [4]float32
+			var sum float32
+			start := offset[y]
+			ci := y * filterLength
+			for i := 0; i < filterLength; i++ {
+				if coeffs[ci+i] {
+					xi := start + i
+					switch {
+					case uint(xi) < uint(maxX):
+						xi *= 4
+					case xi >= maxX:
+						xi = 4 * maxX
+					default:
+						xi = 0
+					}
+					rgba[0] += float32(row[xi+0])
+					rgba[1] += float32(row[xi+1])
+					rgba[2] += float32(row[xi+2])
+					rgba[3] += float32(row[xi+3])
+					sum++
+				}
+			}
+
+			xo := (y-newBounds.Min.Y)*out.Stride + (x-newBounds.Min.X)*4
+			out.Pix[xo+0] = floatToUint8(rgba[0] / sum)
+			out.Pix[xo+1] = floatToUint8(rgba[1] / sum)
+			out.Pix[xo+2] = floatToUint8(rgba[2] / sum)
+			out.Pix[xo+3] = floatToUint8(rgba[3] / sum)
+		}
+	}
+}
+
+func Test_NearestRGBA(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 97, 61))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i*7 + i/13)
+	}
+	sub := img.SubImage(image.Rect(5, 3, 90, 60)).(*image.RGBA)
+
+	for _, in := range []*image.RGBA{img, sub} {
+		for _, size := range []image.Point{{40, 20}, {31, 200}, {300, 17}, {200, 150}} {
+			m := Resize(uint(size.X), uint(size.Y), in, NearestNeighbor).(*image.RGBA)
+
+			b := in.Bounds()
+			p := newPlan(b.Dx(), b.Dy(), size.X, size.Y, float64(b.Dx())/float64(size.X), float64(b.Dy())/float64(size.Y), NearestNeighbor)
+			temp := image.NewRGBA(image.Rect(0, 0, b.Dy(), size.X))
+			want := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+			coeffs, offset, filterLength := p.weightsNearestX()
+			nearestRGBATwoPass(in, temp, p.scaleX, coeffs, offset, filterLength)
+			coeffs, offset, filterLength = p.weightsNearestY()
+			nearestRGBATwoPass(temp, want, p.scaleY, coeffs, offset, filterLength)
+
+			for i := range want.Pix {
+				if m.Pix[i] != want.Pix[i] {
+					t.Fatalf("%v to %v: byte %d: want %d, got %d", b, size, i, want.Pix[i], m.Pix[i])
+				}
+			}
 		}
 	}
 }
@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"math"
+)
+
+// Options configures ResizeWithOptions. The zero value selects the same
+// behavior as Resize.
+type Options struct {
+	// Border selects how pixels outside of the image are sampled.
+	Border BorderMode
+	// UpscaleBlur widens the kernel by the factor 1+UpscaleBlur on axes
+	// that are enlarged, which low-pass filters the result, e.g. to hide
+	// block artifacts of JPEG images. Reductions are not affected.
+	UpscaleBlur float32
+	// ConventionX and ConventionY select how the pixel grids of the
+	// source and the result are aligned horizontally and vertically.
+	ConventionX, ConventionY PixelConvention
+	// Clamp, if not nil, converts the 16-bit premultiplied samples of the
+	// result, which may be out of range, e.g. to soft clip highlights.
+	// It must keep colors below alpha. The default rounds and clamps to
+	// [0,65535].
+	Clamp func(float32) uint16
+	// HighPrecisionTaps, if greater than zero, makes passes whose filter
+	// has more than HighPrecisionTaps taps accumulate in float64. Large
+	// reductions sum hundreds of taps, where float32 rounding errors add
+	// up; enlargements sum few and keep the faster float32 accumulation.
+	HighPrecisionTaps int
+	// LinearLight filters colors in linear light instead of sRGB, which
+	// keeps bright detail on dark backgrounds from darkening. Colors of
+	// the source are taken as sRGB and the result is converted back to
+	// sRGB.
+	LinearLight bool
+	// AntiAlias scales the kernel on axes that are reduced, where it is
+	// stretched by the reduction factor to prevent moire. 0 keeps the
+	// default of 1; larger values blur more. Smaller values keep edges
+	// sharper, down to the unstretched kernel of an enlargement at the
+	// inverse of the reduction factor and below, but can reintroduce
+	// moire on photographic content.
+	AntiAlias float32
+}
+
+// isZero reports whether o is the zero value.
+func (o Options) isZero() bool {
+	return o.Border == Replicate && o.UpscaleBlur == 0 &&
+		o.ConventionX == PixelCenter && o.ConventionY == PixelCenter &&
+		o.Clamp == nil && o.HighPrecisionTaps == 0 && !o.LinearLight &&
+		o.AntiAlias == 0
+}
+
+// weights returns the kernel weights of interp for a pass with dy output
+// samples, moved by shift source pixels.
+func (o Options) weights(dy int, interp InterpolationFunction, scale, shift float64) ([]float32, []int, int) {
+	if scale <= 1 || o.AntiAlias <= 0 {
+		return createWeightsInterp(dy, interp, scale, shift, float64(o.UpscaleBlur))
+	}
+	taps, kernel := interp.kernel()
+	b := math.Max(blur*float64(o.AntiAlias), 1/scale)
+	return createWeightsFloat(dy, taps, b, scale, shift, kernel)
+}
+
+// convolver returns the function that filters a pass with filterLength
+// taps.
+func (o Options) convolver(filterLength int) func(in, out *floatImage, y0, y1 int, coeffs []float32, index []int, filterLength int) {
+	if o.HighPrecisionTaps > 0 && filterLength > o.HighPrecisionTaps {
+		return convolveFloat64
+	}
+	return convolveFloat
+}
+
+// PixelConvention selects how the pixel grid of the result is mapped onto
+// the pixel grid of the source along one axis.
+type PixelConvention int
+
+// PixelConvention constants
+const (
+	// PixelCenter aligns the outer edges of the images, as Resize does:
+	// the center of the result pixel x is sampled at
+	// (x+0.5)*oldWidth/width-0.5 in the source.
+	PixelCenter PixelConvention = iota
+	// AlignCorners aligns the centers of the corner pixels: the result
+	// pixel x is sampled at x*(oldWidth-1)/(width-1) in the source.
+	// Sizes of one pixel fall back to PixelCenter.
+	AlignCorners
+)
+
+// mapping returns the scale and phase that map a result of size dst onto a
+// source of size src according to c, given the scale and phase of
+// PixelCenter.
+func (c PixelConvention) mapping(src, dst int, scale, phase float64) (float64, float64) {
+	if c != AlignCorners || src < 2 || dst < 2 {
+		return scale, phase
+	}
+	s := float64(src-1) / float64(dst-1)
+	return s, phase + 0.5 - 0.5*s
+}
+
+// ResizeWithOptions scales an image like Resize, with the behavior modified
+// by opts. Unless opts is the zero value, the image is processed with float
+// precision and the result is an *image.RGBA64.
+func ResizeWithOptions(width, height uint, img image.Image, interp InterpolationFunction, opts Options) image.Image {
+	if opts.isZero() {
+		return Resize(width, height, img, interp)
+	}
+
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	if !opts.LinearLight {
+		in := newFloatImageFromImage(img)
+		return resizeFloatPhase(in, int(width), int(height), scaleX, scaleY, 0, 0, interp, opts).rgba64(opts.Clamp)
+	}
+	out := resizeFloatPhase(linearFloatImage(img), int(width), int(height), scaleX, scaleY, 0, 0, interp, opts)
+	out.delinearize()
+	return out.rgba64(opts.Clamp)
+}
+
+// ResizeTileable scales a texture that tiles seamlessly such that the result
+// tiles seamlessly as well. Pixels beyond a border are taken from the
+// opposite border on both axes, i.e. it is a shorthand for ResizeWithOptions
+// with the Wrap border mode.
+func ResizeTileable(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	return ResizeWithOptions(width, height, img, interp, Options{Border: Wrap})
+}
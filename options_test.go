@@ -0,0 +1,405 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_ResizeWithDefaultOptions(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 20))
+	if _, ok := ResizeWithOptions(10, 10, img, Bilinear, Options{}).(*image.Gray); !ok {
+		t.Error("default options do not use Resize")
+	}
+}
+
+func Test_BorderRenormalize(t *testing.T) {
+	// Vertical gradient with a dark top row.
+	img := image.NewGray16(image.Rect(0, 0, 4, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray16(x, y, color.Gray16{uint16(1000 * y)})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		replicate := ResizeWithOptions(4, 8, img, interp, Options{Border: Replicate})
+		renormalize := ResizeWithOptions(4, 8, img, interp, Options{Border: Renormalize})
+
+		rep, _, _, _ := replicate.At(0, 0).RGBA()
+		ren, _, _, _ := renormalize.At(0, 0).RGBA()
+		if ren <= rep {
+			t.Errorf("%v: top row %d is biased towards the edge as much as replicate %d", interp, ren, rep)
+		}
+		rep, _, _, _ = replicate.At(0, 7).RGBA()
+		ren, _, _, _ = renormalize.At(0, 7).RGBA()
+		if ren >= rep {
+			t.Errorf("%v: bottom row %d is biased towards the edge as much as replicate %d", interp, ren, rep)
+		}
+	}
+}
+
+func Test_BorderRenormalizeSolid(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 9, 9))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+
+	out := ResizeWithOptions(4, 4, img, Lanczos3, Options{Border: Renormalize})
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if r, _, _, _ := out.At(x, y).RGBA(); r != 0x8080 {
+				t.Fatalf("(%d,%d): want %d, got %d", x, y, 0x8080, r)
+			}
+		}
+	}
+}
+
+func Test_ResizeTileable(t *testing.T) {
+	tile := image.NewGray16(image.Rect(0, 0, 32, 24))
+	for y := 0; y < 24; y++ {
+		tile.SetGray16(31, y, color.Gray16{0xffff})
+	}
+
+	m := ResizeTileable(8, 6, tile, Bilinear)
+	if r, _, _, _ := m.At(0, 3).RGBA(); r == 0 {
+		t.Error("left column does not interpolate from the right border")
+	}
+	if r, _, _, _ := ResizeWithOptions(8, 6, tile, Bilinear, Options{Border: Replicate}).At(0, 3).RGBA(); r != 0 {
+		t.Errorf("replicate reads across the border: %d", r)
+	}
+
+	// Resizing two tiles gives two tiles of the resized texture.
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 32; x++ {
+			tile.SetGray16(x, y, color.Gray16{uint16(x*x*50 + y*1000)})
+		}
+	}
+	double := image.NewGray16(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			double.SetGray16(x, y, tile.Gray16At(x%32, y%24))
+		}
+	}
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m = ResizeTileable(8, 6, tile, interp)
+		md := ResizeTileable(16, 12, double, interp)
+		for y := 0; y < 12; y++ {
+			for x := 0; x < 16; x++ {
+				if m.At(x%8, y%6) != md.At(x, y) {
+					t.Fatalf("%v: (%d,%d): want %v, got %v", interp, x, y, m.At(x%8, y%6), md.At(x, y))
+				}
+			}
+		}
+	}
+}
+
+func Test_WrapGradientShift(t *testing.T) {
+	// A ramp whose right border jumps back to the left border.
+	tile := image.NewGray16(image.Rect(0, 0, 32, 8))
+	shifted := image.NewGray16(tile.Rect)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 32; x++ {
+			tile.SetGray16(x, y, color.Gray16{uint16(x * 2000)})
+			shifted.SetGray16((x+4)%32, y, color.Gray16{uint16(x * 2000)})
+		}
+	}
+
+	// Moving the tile by one result pixel moves the result, edges included.
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		m := ResizeTileable(8, 2, tile, interp)
+		ms := ResizeTileable(8, 2, shifted, interp)
+		for y := 0; y < 2; y++ {
+			for x := 0; x < 8; x++ {
+				if want, got := m.At(x, y), ms.At((x+1)%8, y); want != got {
+					t.Fatalf("%v: (%d,%d): want %v, got %v", interp, x, y, want, got)
+				}
+			}
+		}
+	}
+}
+
+func Test_BorderMirror(t *testing.T) {
+	// Source pixels abcd at 0..3, sampled at -2, -1, 4 and 5.
+	tests := []struct {
+		border BorderMode
+		want   [4]int
+	}{
+		{MirrorEdge, [4]int{1, 0, 3, 2}},   // ba|abcd|dc
+		{MirrorNoEdge, [4]int{2, 1, 2, 1}}, // cb|abcd|cb
+	}
+	for _, test := range tests {
+		coeffs := []float32{1, 1, 1, 1}
+		index := borderIndex(coeffs, []int{-2, 4}, 2, 4, test.border)
+		if got := [4]int{index[0], index[1], index[2], index[3]}; got != test.want {
+			t.Errorf("border %d: want %v, got %v", test.border, test.want, got)
+		}
+	}
+
+	// Far outside of the image, the reflections repeat.
+	for x := -20; x < 24; x++ {
+		if got, want := mirrorEdgeBorder(x, 4), mirrorEdgeBorder(x+8, 4); got != want {
+			t.Errorf("MirrorEdge is not periodic at %d", x)
+		}
+		if got, want := mirrorNoEdgeBorder(x, 4), mirrorNoEdgeBorder(x+6, 4); got != want {
+			t.Errorf("MirrorNoEdge is not periodic at %d", x)
+		}
+		if got := mirrorNoEdgeBorder(x, 1); got != 0 {
+			t.Errorf("single pixel: want 0, got %d", got)
+		}
+	}
+
+	// Only the left column is bright, so mirroring without the edge sees
+	// less of it near the border than repeating it.
+	img := image.NewGray16(image.Rect(0, 0, 32, 8))
+	for y := 0; y < 8; y++ {
+		img.SetGray16(0, y, color.Gray16{0xffff})
+	}
+	edge, _, _, _ := ResizeWithOptions(8, 2, img, Lanczos3, Options{Border: MirrorEdge}).At(0, 0).RGBA()
+	noEdge, _, _, _ := ResizeWithOptions(8, 2, img, Lanczos3, Options{Border: MirrorNoEdge}).At(0, 0).RGBA()
+	if noEdge >= edge {
+		t.Errorf("MirrorNoEdge %d is not darker than MirrorEdge %d", noEdge, edge)
+	}
+}
+
+func Test_BorderMirrorCheckerboard(t *testing.T) {
+	// Reflected about its border pixels, a checkerboard continues as a
+	// checkerboard, so its reduction is a flat gray up to the border.
+	img := image.NewGray16(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if (x+y)%2 == 0 {
+				img.SetGray16(x, y, color.Gray16{0xffff})
+			}
+		}
+	}
+
+	mirrored := ResizeWithOptions(8, 8, img, Bilinear, Options{Border: MirrorNoEdge})
+	center, _, _, _ := mirrored.At(4, 4).RGBA()
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if v, _, _, _ := mirrored.At(x, y).RGBA(); absDiff(v, center) > 1 {
+				t.Errorf("(%d,%d): want %#x like the center, got %#x", x, y, center, v)
+			}
+		}
+	}
+
+	// Clamping repeats the border pixel, which breaks the pattern.
+	replicated := ResizeWithOptions(8, 8, img, Bilinear, Options{Border: Replicate})
+	if v, _, _, _ := replicated.At(0, 0).RGBA(); absDiff(v, center) <= 1 {
+		t.Error("replicated border does not differ from the center")
+	}
+}
+
+// highFrequencyEnergy sums the squared differences of horizontally and
+// vertically adjacent pixels.
+func highFrequencyEnergy(img image.Image) float64 {
+	b := img.Bounds()
+	var e float64
+	for y := b.Min.Y; y < b.Max.Y-1; y++ {
+		for x := b.Min.X; x < b.Max.X-1; x++ {
+			v, _, _, _ := img.At(x, y).RGBA()
+			h, _, _, _ := img.At(x+1, y).RGBA()
+			d, _, _, _ := img.At(x, y+1).RGBA()
+			e += (float64(v)-float64(h))*(float64(v)-float64(h)) + (float64(v)-float64(d))*(float64(v)-float64(d))
+		}
+	}
+	return e
+}
+
+func Test_UpscaleBlur(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if (x/2+y/2)%2 == 0 {
+				img.SetGray(x, y, color.Gray{0xff})
+			}
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		sharp := highFrequencyEnergy(ResizeWithOptions(32, 32, img, interp, Options{}))
+		soft := highFrequencyEnergy(ResizeWithOptions(32, 32, img, interp, Options{UpscaleBlur: 0.5}))
+		if soft >= sharp {
+			t.Errorf("%v: energy %g with UpscaleBlur, %g without", interp, soft, sharp)
+		}
+	}
+
+	// Reductions are not affected.
+	want := resizeFloat(newFloatImageFromImage(img), 4, 4, 2, 2, Bilinear).RGBA64()
+	got := ResizeWithOptions(4, 4, img, Bilinear, Options{UpscaleBlur: 0.5}).(*image.RGBA64)
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatal("UpscaleBlur changes a reduction")
+		}
+	}
+}
+
+func Test_PixelConventionPerAxis(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 5, 5))
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			img.SetRGBA64(x, y, color.RGBA64{uint16(x * 9000), uint16(y * 9000), 0, 0xffff})
+		}
+	}
+
+	m := ResizeWithOptions(9, 9, img, Bilinear, Options{ConventionX: AlignCorners, ConventionY: PixelCenter}).(*image.RGBA64)
+	// Along x the corner pixels keep their values and the others are
+	// halfway between two source pixels.
+	for x := 0; x < 9; x++ {
+		if r := m.RGBA64At(x, 4).R; absDiff(uint32(r), uint32(x*4500)) > 1 {
+			t.Errorf("x=%d: want %d, got %d", x, x*4500, r)
+		}
+	}
+	// Along y the result pixel y is sampled at (y+0.5)*5/9-0.5.
+	for y := 1; y < 8; y++ {
+		want := ((float64(y)+0.5)*5/9 - 0.5) * 9000
+		if g := m.RGBA64At(4, y).G; math.Abs(float64(g)-want) > 1 {
+			t.Errorf("y=%d: want %.0f, got %d", y, want, g)
+		}
+	}
+}
+
+func Test_OptionsClamp(t *testing.T) {
+	// A bright bar on gray, which makes Lanczos3 overshoot.
+	img := image.NewGray16(image.Rect(0, 0, 40, 4))
+	for x := 0; x < 40; x++ {
+		v := uint16(0x8000)
+		if x >= 18 && x < 22 {
+			v = 0xffff
+		}
+		for y := 0; y < 4; y++ {
+			img.SetGray16(x, y, color.Gray16{v})
+		}
+	}
+
+	// Identity below a knee, compresses everything above into the range.
+	const knee = 0xc000
+	softKnee := func(v float32) uint16 {
+		if v <= knee {
+			return clampFloatUint16(v)
+		}
+		over := float64(v - knee)
+		return uint16(knee + (0xffff-knee)*(1-math.Exp(-over/(0xffff-knee))) + 0.5)
+	}
+
+	hard := ResizeWithOptions(100, 4, img, Lanczos3, Options{Clamp: clampFloatUint16}).(*image.RGBA64)
+	soft := ResizeWithOptions(100, 4, img, Lanczos3, Options{Clamp: softKnee}).(*image.RGBA64)
+	var clipped, differ int
+	for x := 0; x < 100; x++ {
+		h, s := hard.RGBA64At(x, 2).R, soft.RGBA64At(x, 2).R
+		if h == 0xffff {
+			clipped++
+		}
+		if h > knee && h != s {
+			differ++
+		}
+		if h <= knee && h != s {
+			t.Errorf("x=%d: value %d below the knee changed to %d", x, h, s)
+		}
+	}
+	if clipped < 2 {
+		t.Fatalf("no overshoot is clipped by default")
+	}
+	if differ == 0 {
+		t.Error("highlights are not changed by the soft knee")
+	}
+}
+
+func Test_HighPrecisionTaps(t *testing.T) {
+	opts := Options{HighPrecisionTaps: 16}
+
+	// A 50:1 reduction with Lanczos3 sums 300 taps.
+	const srcWidth, width = 5000, 100
+	coeffs, offset, filterLength := createWeightsInterp(width, Lanczos3, srcWidth/width, 0, 0)
+	index := borderIndex(coeffs, offset, filterLength, srcWidth, Replicate)
+	if reflect.ValueOf(opts.convolver(filterLength)).Pointer() != reflect.ValueOf(convolveFloat64).Pointer() {
+		t.Fatalf("%d taps do not accumulate in float64", filterLength)
+	}
+
+	in := newFloatImage(image.Rect(0, 0, srcWidth, 1), 1)
+	for x := range in.Pix {
+		in.Pix[x] = float32(x*7919%65536) + 0.25
+	}
+	var errs [2]float64
+	for k, convolve := range []func(in, out *floatImage, y0, y1 int, coeffs []float32, index []int, filterLength int){convolveFloat, convolveFloat64} {
+		out := newFloatImage(image.Rect(0, 0, 1, width), 1)
+		convolve(in, out, 0, width, coeffs, index, filterLength)
+		for y := 0; y < width; y++ {
+			var want, sum float64
+			for i := 0; i < filterLength; i++ {
+				coeff := float64(coeffs[y*filterLength+i])
+				want += coeff * float64(in.Pix[index[y*filterLength+i]])
+				sum += coeff
+			}
+			errs[k] += math.Abs(float64(out.Pix[y]) - want/sum)
+		}
+	}
+	if errs[1] >= errs[0] {
+		t.Errorf("float64 accumulation has error %v, float32 %v", errs[1], errs[0])
+	}
+
+	// A 2x enlargement with Bilinear sums 2 taps.
+	_, _, filterLength = createWeightsInterp(200, Bilinear, 0.5, 0, 0)
+	if reflect.ValueOf(opts.convolver(filterLength)).Pointer() != reflect.ValueOf(convolveFloat).Pointer() {
+		t.Errorf("%d taps do not accumulate in float32", filterLength)
+	}
+}
+
+func Test_OptionsLinearLight(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if (x+y)%2 == 0 {
+				img.SetGray(x, y, color.Gray{0xff})
+			}
+		}
+	}
+
+	linear := ResizeWithOptions(1, 1, img, Area, Options{LinearLight: true})
+	if r, _, _, _ := linear.At(0, 0).RGBA(); absDiff(r>>8, 188) > 1 {
+		t.Errorf("linear light: want 188, got %d", r>>8)
+	}
+	if r, _, _, _ := Resize(1, 1, img, Area).At(0, 0).RGBA(); absDiff(r>>8, 128) > 1 {
+		t.Errorf("sRGB: want 128, got %d", r>>8)
+	}
+}
+
+func Test_OptionsAntiAlias(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 60, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			if (x/3+y/3)%2 == 0 {
+				img.SetGray(x, y, color.Gray{0xff})
+			}
+		}
+	}
+
+	// contrast returns the range of the red channel in row y.
+	contrast := func(m image.Image, y int) uint32 {
+		min, max := uint32(0xffff), uint32(0)
+		for x := 0; x < m.Bounds().Dx(); x++ {
+			r, _, _, _ := m.At(x, y).RGBA()
+			if r < min {
+				min = r
+			}
+			if r > max {
+				max = r
+			}
+		}
+		return max - min
+	}
+
+	plain := ResizeWithOptions(25, 25, img, Bilinear, Options{AntiAlias: 1})
+	sharp := ResizeWithOptions(25, 25, img, Bilinear, Options{AntiAlias: 0.01})
+	soft := ResizeWithOptions(25, 25, img, Bilinear, Options{AntiAlias: 2})
+	c, s, b := contrast(plain, 10), contrast(sharp, 10), contrast(soft, 10)
+	if !(s > c && c > b) {
+		t.Errorf("want contrast decreasing with AntiAlias, got %#x, %#x, %#x", s, c, b)
+	}
+	if m := maxStep(sharp, 10); m <= maxStep(plain, 10) {
+		t.Errorf("edges not sharper without anti-aliasing: %#x", m)
+	}
+}
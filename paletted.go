@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+)
+
+// ResizePalettedSamePalette scales a paletted image like Resize and maps the
+// result back to the palette of img, so that e.g. the frames of an animation
+// keep a common palette. Every pixel gets the palette entry closest to its
+// interpolated color.
+func ResizePalettedSamePalette(width, height uint, img *image.Paletted, interp InterpolationFunction) *image.Paletted {
+	m := Resize(width, height, img, interp)
+	if p, ok := m.(*image.Paletted); ok {
+		return p
+	}
+
+	b := m.Bounds()
+	out := image.NewPaletted(b, img.Palette)
+	if len(img.Palette) == 0 {
+		return out
+	}
+	cache := make(map[color.RGBA64]uint8)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBA64Model.Convert(m.At(x, y)).(color.RGBA64)
+			i, ok := cache[c]
+			if !ok {
+				i = uint8(img.Palette.Index(c))
+				cache[c] = i
+			}
+			out.Pix[out.PixOffset(x, y)] = i
+		}
+	}
+	return out
+}
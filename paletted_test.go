@@ -0,0 +1,90 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func Test_ResizePalettedSamePalette(t *testing.T) {
+	palette := color.Palette{
+		color.RGBA{0, 0, 0, 0xff},
+		color.RGBA{0xff, 0, 0, 0xff},
+		color.RGBA{0, 0xff, 0, 0xff},
+		color.RGBA{0x80, 0x80, 0x80, 0xff},
+	}
+	img := image.NewPaletted(image.Rect(0, 0, 32, 24), palette)
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetColorIndex(x, y, uint8((x/4+y/3)%len(palette)))
+		}
+	}
+
+	out := ResizePalettedSamePalette(13, 0, img, Lanczos3)
+	if out.Bounds() != image.Rect(0, 0, 13, 10) {
+		t.Errorf("want bounds %v, got %v", image.Rect(0, 0, 13, 10), out.Bounds())
+	}
+	if len(out.Palette) != len(palette) {
+		t.Fatalf("palette changed: %v", out.Palette)
+	}
+	for _, i := range out.Pix {
+		if int(i) >= len(palette) {
+			t.Fatalf("invalid palette index %d", i)
+		}
+	}
+}
+
+func Test_ResizePalettedSamePaletteSolid(t *testing.T) {
+	palette := color.Palette{color.Black, color.RGBA{0x12, 0x34, 0x56, 0xff}}
+	img := image.NewPaletted(image.Rect(0, 0, 10, 10), palette)
+	for i := range img.Pix {
+		img.Pix[i] = 1
+	}
+
+	out := ResizePalettedSamePalette(4, 4, img, Bilinear)
+	for _, i := range out.Pix {
+		if i != 1 {
+			t.Fatalf("want index 1, got %d", i)
+		}
+	}
+}
+
+func Test_ResizePaletted(t *testing.T) {
+	palette := color.Palette{
+		color.RGBA{0, 0, 0, 0xff},
+		color.RGBA{0xff, 0, 0, 0xff},
+		color.NRGBA{0, 0xff, 0, 0x80},
+		color.Transparent,
+	}
+	img := image.NewPaletted(image.Rect(3, 2, 43, 32), palette)
+	for i := range img.Pix {
+		img.Pix[i] = uint8((i*7 + i/5) % len(palette))
+	}
+	rgba64 := image.NewRGBA64(img.Bounds())
+	draw.Draw(rgba64, rgba64.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+		for _, size := range []image.Point{{17, 13}, {90, 70}} {
+			got := Resize(uint(size.X), uint(size.Y), img, interp)
+			want := Resize(uint(size.X), uint(size.Y), rgba64, interp)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%v interp %v: differs from the RGBA64 result", size, interp)
+			}
+		}
+	}
+}
+
+func Benchmark_ResizePaletted(b *testing.B) {
+	img := image.NewPaletted(image.Rect(0, 0, 500, 400), palette.Plan9)
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Resize(250, 200, img, Lanczos3)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizePerChannel scales an image like Resize, but filters every channel
+// with its own interpolation function, e.g. the green channel of demosaiced
+// sensor data sharper than red and blue. Channels are filtered as 16-bit
+// premultiplied values; colors that end up above alpha are clamped to it.
+// The result is an *image.RGBA64.
+func ResizePerChannel(width, height uint, img image.Image, interpR, interpG, interpB, interpA InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	in := newFloatImageFromImage(img)
+	out := newFloatImage(image.Rect(0, 0, int(width), int(height)), 4)
+	channel := newFloatImage(in.Rect, 1)
+	for c, interp := range [4]InterpolationFunction{interpR, interpG, interpB, interpA} {
+		for i := range channel.Pix {
+			channel.Pix[i] = in.Pix[4*i+c]
+		}
+		resized := resizeFloat(channel, int(width), int(height), scaleX, scaleY, interp)
+		for i, v := range resized.Pix {
+			out.Pix[4*i+c] = v
+		}
+	}
+
+	for i := 0; i < len(out.Pix); i += 4 {
+		a := out.Pix[i+3]
+		for c := 0; c < 3; c++ {
+			if out.Pix[i+c] > a {
+				out.Pix[i+c] = a
+			}
+		}
+	}
+	return out.RGBA64()
+}
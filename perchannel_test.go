@@ -0,0 +1,43 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizePerChannel(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			v := uint8(x*6 + y)
+			img.SetRGBA(x, y, color.RGBA{v, v, v, 0xff})
+		}
+	}
+
+	m := ResizePerChannel(17, 23, img, NearestNeighbor, Lanczos3, Bilinear, Bilinear).(*image.RGBA64)
+	in := newFloatImageFromImage(img)
+	nearest := resizeFloat(in, 17, 23, 40.0/17, 30.0/23, NearestNeighbor).RGBA64()
+	lanczos := resizeFloat(in, 17, 23, 40.0/17, 30.0/23, Lanczos3).RGBA64()
+	differ := false
+	for y := 0; y < 23; y++ {
+		for x := 0; x < 17; x++ {
+			c := m.RGBA64At(x, y)
+			if want := nearest.RGBA64At(x, y).R; c.R != want {
+				t.Errorf("(%d,%d): want nearest red %d, got %d", x, y, want, c.R)
+			}
+			if want := lanczos.RGBA64At(x, y).G; c.G != want {
+				t.Errorf("(%d,%d): want Lanczos3 green %d, got %d", x, y, want, c.G)
+			}
+			if c.A != 0xffff {
+				t.Errorf("(%d,%d): want opaque, got alpha %d", x, y, c.A)
+			}
+			if c.R != c.G {
+				differ = true
+			}
+		}
+	}
+	if !differ {
+		t.Error("red and green channels are filtered the same")
+	}
+}
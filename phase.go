@@ -0,0 +1,41 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizePhase scales an image like Resize, but moves the sampling grid of
+// the output by phaseX and phaseY source pixels, e.g. to resample frames
+// with known subpixel shifts onto a common grid.
+// A phase of 0 samples the same positions as Resize, so an unchanged size
+// reproduces the source. A phase of 0.5 samples halfway between two source
+// pixels. The phase is usually in [0,1), other values are allowed.
+// The result is an *image.RGBA64.
+func ResizePhase(width, height uint, phaseX, phaseY float32, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	in := newFloatImageFromImage(img)
+	return resizeFloatPhase(in, int(width), int(height), scaleX, scaleY, float64(phaseX), float64(phaseY), interp, Options{}).RGBA64()
+}
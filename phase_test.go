@@ -0,0 +1,41 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizePhase(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetGray16(x, y, color.Gray16{uint16(1000*x + 10000*y)})
+		}
+	}
+
+	var testData = []struct {
+		phaseX, phaseY float32
+		x, y           int
+		expected       uint16
+	}{
+		{0, 0, 0, 0, 0},
+		{0, 0, 2, 1, 12000},
+		{0.5, 0, 0, 0, 500},
+		{0.5, 0, 2, 1, 12500},
+		{0.5, 0, 3, 0, 3000},
+		{0, 0.5, 1, 0, 6000},
+		{0.5, 0.5, 1, 1, 16500},
+		{0.25, 0, 1, 0, 1250},
+	}
+	for _, test := range testData {
+		out := ResizePhase(4, 3, test.phaseX, test.phaseY, img, Bilinear)
+		if out.Bounds() != img.Bounds() {
+			t.Fatalf("want bounds %v, got %v", img.Bounds(), out.Bounds())
+		}
+		c := color.Gray16Model.Convert(out.At(test.x, test.y)).(color.Gray16)
+		if absDiff(uint32(c.Y), uint32(test.expected)) > 1 {
+			t.Errorf("phase (%v,%v) at (%d,%d): want %d, got %d", test.phaseX, test.phaseY, test.x, test.y, test.expected, c.Y)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ResizePlan holds the filter weights of a resize between fixed sizes, so
+// that many images of the same size, e.g. the frames of a video, can be
+// scaled without computing the weights again. A plan is immutable and may
+// be used by several goroutines at the same time.
+type ResizePlan struct {
+	srcWidth, srcHeight int
+	width, height       int
+	scaleX, scaleY      float64
+	interp              InterpolationFunction
+
+	// workers is the number of goroutines of a pass, 0 means GOMAXPROCS.
+	workers int
+
+	// step, if not nil, is called by runPass before every chunk of a pass
+	// with the pass, 0 or 1, and the finished and total columns of its
+	// output. It is only set on plans that are not shared.
+	step func(pass, done, total int) error
+
+	// A window plan computes the pixels in window of the result of parent
+	// with the weights of parent. Its source image starts at row srcY0 of
+	// the source of parent.
+	parent *ResizePlan
+	window image.Rectangle
+	srcY0  int
+
+	once8, once16, onceNearest sync.Once
+	x8, y8                     weights8
+	x16, y16                   weights16
+	xNearest, yNearest         weightsNearest
+
+	// builds counts the computed weight tables.
+	builds int32
+}
+
+type weights8 struct {
+	coeffs       []int16
+	offset       []int
+	filterLength int
+}
+
+type weights16 struct {
+	coeffs       []int32
+	offset       []int
+	filterLength int
+}
+
+type weightsNearest struct {
+	coeffs       []bool
+	offset       []int
+	filterLength int
+}
+
+// Plan computes the weights to scale images of srcWidth x srcHeight pixels
+// to width x height pixels using the interpolation function interp. The
+// handling of the width and height parameters is the same as in Resize.
+func Plan(srcWidth, srcHeight, width, height int, interp InterpolationFunction) *ResizePlan {
+	// Source images have no pixels, there are no weights to compute
+	if srcWidth <= 0 || srcHeight <= 0 {
+		return newPlan(srcWidth, srcHeight, width, height, 1, 1, interp)
+	}
+
+	w, h, scaleX, scaleY := scaledSize(uint(width), uint(height), srcWidth, srcHeight)
+	width, height = int(w), int(h)
+
+	p := newPlan(srcWidth, srcHeight, width, height, scaleX, scaleY, interp)
+	if interp == NearestNeighbor {
+		p.weightsNearestX()
+	} else {
+		p.weights8X()
+		p.weights16X()
+	}
+	return p
+}
+
+// newPlan returns a plan that computes its weights when they are first
+// used.
+func newPlan(srcWidth, srcHeight, width, height int, scaleX, scaleY float64, interp InterpolationFunction) *ResizePlan {
+	return &ResizePlan{
+		srcWidth:  srcWidth,
+		srcHeight: srcHeight,
+		width:     width,
+		height:    height,
+		scaleX:    scaleX,
+		scaleY:    scaleY,
+		interp:    interp,
+	}
+}
+
+// Apply scales img with the weights of the plan. The result is the same as
+// that of Resize. Images whose size differs from the source size of the
+// plan are passed to Resize.
+func (p *ResizePlan) Apply(img image.Image) image.Image {
+	b := img.Bounds()
+	if b.Dx() != p.srcWidth || b.Dy() != p.srcHeight {
+		return Resize(uint(p.width), uint(p.height), img, p.interp)
+	}
+	if p.width == p.srcWidth && p.height == p.srcHeight {
+		return img
+	}
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+	return p.apply(img)
+}
+
+func (p *ResizePlan) build8() {
+	x, y := &p.x8, &p.y8
+	if p.parent != nil {
+		x.coeffs, x.offset, x.filterLength = p.parent.weights8X()
+		y.coeffs, y.offset, y.filterLength = p.parent.weights8Y()
+		x.coeffs = x.coeffs[p.window.Min.X*x.filterLength:]
+		y.coeffs = y.coeffs[p.window.Min.Y*y.filterLength:]
+		x.offset, y.offset = p.windowOffsets(x.offset, y.offset)
+		return
+	}
+	taps, kernel := p.interp.kernel()
+	x.coeffs, x.offset, x.filterLength = createWeights8(p.width, taps, blur, p.scaleX, kernel)
+	y.coeffs, y.offset, y.filterLength = createWeights8(p.height, taps, blur, p.scaleY, kernel)
+	atomic.AddInt32(&p.builds, 1)
+}
+
+func (p *ResizePlan) build16() {
+	x, y := &p.x16, &p.y16
+	if p.parent != nil {
+		x.coeffs, x.offset, x.filterLength = p.parent.weights16X()
+		y.coeffs, y.offset, y.filterLength = p.parent.weights16Y()
+		x.coeffs = x.coeffs[p.window.Min.X*x.filterLength:]
+		y.coeffs = y.coeffs[p.window.Min.Y*y.filterLength:]
+		x.offset, y.offset = p.windowOffsets(x.offset, y.offset)
+		return
+	}
+	taps, kernel := p.interp.kernel()
+	x.coeffs, x.offset, x.filterLength = createWeights16(p.width, taps, blur, p.scaleX, kernel)
+	y.coeffs, y.offset, y.filterLength = createWeights16(p.height, taps, blur, p.scaleY, kernel)
+	atomic.AddInt32(&p.builds, 1)
+}
+
+func (p *ResizePlan) buildNearest() {
+	x, y := &p.xNearest, &p.yNearest
+	if p.parent != nil {
+		x.coeffs, x.offset, x.filterLength = p.parent.weightsNearestX()
+		y.coeffs, y.offset, y.filterLength = p.parent.weightsNearestY()
+		x.coeffs = x.coeffs[p.window.Min.X*x.filterLength:]
+		y.coeffs = y.coeffs[p.window.Min.Y*y.filterLength:]
+		x.offset, y.offset = p.windowOffsets(x.offset, y.offset)
+		return
+	}
+	taps, _ := p.interp.kernel()
+	x.coeffs, x.offset, x.filterLength = createWeightsNearest(p.width, taps, blur, p.scaleX)
+	y.coeffs, y.offset, y.filterLength = createWeightsNearest(p.height, taps, blur, p.scaleY)
+	atomic.AddInt32(&p.builds, 1)
+}
+
+// parallelism returns the number of goroutines of a pass of p. It is at
+// most the height of the result and of the transposed temporary image, so
+// that every goroutine gets rows to filter.
+func (p *ResizePlan) parallelism() int {
+	n := p.workers
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	if n > p.width {
+		n = p.width
+	}
+	if n > p.height {
+		n = p.height
+	}
+	return n
+}
+
+// passColumns is the number of columns of the output of a pass that
+// runPass computes between two calls of the step function of a plan.
+const passColumns = 64
+
+// runPass calls fn concurrently on p.parallelism() slices of the rows of
+// out, the output of the first (pass 0) or the second (pass 1) pass of
+// apply. If p has a step function, out is computed in chunks of passColumns
+// columns, i.e. of source rows in the first pass, and step is called from
+// the goroutine of the caller before every chunk; once it returns an error,
+// the rest of the pass is skipped.
+func (p *ResizePlan) runPass(pass int, out imageWithSubImage, fn func(slice image.Image)) {
+	cpus := p.parallelism()
+	run := func(img imageWithSubImage) {
+		wg := sync.WaitGroup{}
+		wg.Add(cpus)
+		for i := 0; i < cpus; i++ {
+			slice := makeSlice(img, i, cpus)
+			go func() {
+				defer wg.Done()
+				fn(slice)
+			}()
+		}
+		wg.Wait()
+	}
+	if p.step == nil {
+		run(out)
+		return
+	}
+
+	b := out.Bounds()
+	for x := b.Min.X; x < b.Max.X; x += passColumns {
+		if p.step(pass, x-b.Min.X, b.Dx()) != nil {
+			return
+		}
+		chunk := image.Rect(x, b.Min.Y, x+passColumns, b.Max.Y).Intersect(b)
+		run(out.SubImage(chunk).(imageWithSubImage))
+	}
+}
+
+// windowPlan returns a plan for the pixels in r of the result of p. Its
+// source image consists of the srcHeight rows of the source of p starting
+// at row srcY0, which must include all rows read by r.
+func (p *ResizePlan) windowPlan(r image.Rectangle, srcY0, srcHeight int) *ResizePlan {
+	w := newPlan(p.srcWidth, srcHeight, r.Dx(), r.Dy(), p.scaleX, p.scaleY, p.interp)
+	w.parent, w.window, w.srcY0 = p, r, srcY0
+	w.workers = p.workers
+	return w
+}
+
+// windowOffsets returns the offsets of the window of a window plan, given
+// the offsets of its parent.
+func (p *ResizePlan) windowOffsets(x, y []int) ([]int, []int) {
+	x = x[p.window.Min.X:p.window.Max.X]
+	y = y[p.window.Min.Y:p.window.Max.Y]
+	if p.srcY0 != 0 {
+		shifted := make([]int, len(y))
+		for i, v := range y {
+			shifted[i] = v - p.srcY0
+		}
+		y = shifted
+	}
+	return x, y
+}
+
+// weights8X returns the 8-bit weights of the horizontal pass.
+func (p *ResizePlan) weights8X() ([]int16, []int, int) {
+	p.once8.Do(p.build8)
+	return p.x8.coeffs, p.x8.offset, p.x8.filterLength
+}
+
+// weights8Y returns the 8-bit weights of the vertical pass.
+func (p *ResizePlan) weights8Y() ([]int16, []int, int) {
+	p.once8.Do(p.build8)
+	return p.y8.coeffs, p.y8.offset, p.y8.filterLength
+}
+
+// weights16X returns the 16-bit weights of the horizontal pass.
+func (p *ResizePlan) weights16X() ([]int32, []int, int) {
+	p.once16.Do(p.build16)
+	return p.x16.coeffs, p.x16.offset, p.x16.filterLength
+}
+
+// weights16Y returns the 16-bit weights of the vertical pass.
+func (p *ResizePlan) weights16Y() ([]int32, []int, int) {
+	p.once16.Do(p.build16)
+	return p.y16.coeffs, p.y16.offset, p.y16.filterLength
+}
+
+// weightsNearestX returns the nearest-neighbor taps of the horizontal pass.
+func (p *ResizePlan) weightsNearestX() ([]bool, []int, int) {
+	p.onceNearest.Do(p.buildNearest)
+	return p.xNearest.coeffs, p.xNearest.offset, p.xNearest.filterLength
+}
+
+// weightsNearestY returns the nearest-neighbor taps of the vertical pass.
+func (p *ResizePlan) weightsNearestY() ([]bool, []int, int) {
+	p.onceNearest.Do(p.buildNearest)
+	return p.yNearest.coeffs, p.yNearest.offset, p.yNearest.filterLength
+}
@@ -0,0 +1,86 @@
+package resize
+
+import (
+	"image"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func Test_PlanMatchesResize(t *testing.T) {
+	imgs := []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 64, 48)),
+		image.NewYCbCr(image.Rect(0, 0, 64, 48), image.YCbCrSubsampleRatio420),
+		image.NewGray16(image.Rect(0, 0, 64, 48)),
+		image.NewCMYK(image.Rect(0, 0, 64, 48)),
+	}
+	for i, img := range imgs {
+		pix := reflect.ValueOf(img).Elem().FieldByName("Pix")
+		if !pix.IsValid() {
+			pix = reflect.ValueOf(img).Elem().FieldByName("Y")
+		}
+		for j := 0; j < pix.Len(); j++ {
+			pix.Index(j).SetUint(uint64(j*7+i) % 256)
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+		p := Plan(64, 48, 25, 0, interp)
+		for _, img := range imgs {
+			if got, want := p.Apply(img), Resize(25, 0, img, interp); !reflect.DeepEqual(got, want) {
+				t.Errorf("%v %T: plan differs from Resize", interp, img)
+			}
+		}
+	}
+}
+
+func Test_PlanReusesWeights(t *testing.T) {
+	p := Plan(64, 48, 30, 20, Bicubic)
+	builds := p.builds
+
+	frame := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Apply(frame)
+		}()
+	}
+	wg.Wait()
+	if p.builds != builds {
+		t.Errorf("weights computed %d times after planning", p.builds-builds)
+	}
+}
+
+func Test_PlanEmptySource(t *testing.T) {
+	for _, srcWidth := range []int{0, 5} {
+		img := image.NewRGBA(image.Rect(0, 0, srcWidth, 0))
+		if m := Plan(srcWidth, 0, 5, 0, Bilinear).Apply(img); m != img {
+			t.Errorf("width %d: want the source image, got %T with bounds %v", srcWidth, m, m.Bounds())
+		}
+	}
+}
+
+// Benchmark_Lanczos3_RGBA_Plan is Benchmark_Lanczos3_RGBA with the weight
+// tables computed once by a plan instead of by every call.
+func Benchmark_Lanczos3_RGBA_Plan(b *testing.B) {
+	m := image.NewRGBA(image.Rect(0, 0, benchMaxX, benchMaxY))
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+			i := m.PixOffset(x, y)
+			m.Pix[i+0] = uint8(y + 4*x)
+			m.Pix[i+1] = uint8(y + 4*x)
+			m.Pix[i+2] = uint8(y + 4*x)
+			m.Pix[i+3] = uint8(4*y + x)
+		}
+	}
+
+	p := Plan(benchMaxX, benchMaxY, benchWidth, benchHeight, Lanczos3)
+	var out image.Image
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = p.Apply(m)
+	}
+	out.At(0, 0)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeToPlanar scales an image like Resize and returns the red, green and
+// blue channels of the result as separate, tightly packed planes of
+// width*height samples in row-major order, as expected by many machine
+// learning frameworks. The samples are the upper 8 bits of the
+// premultiplied colors; alpha is dropped. The handling of the width and
+// height parameters is the same as in Resize.
+func ResizeToPlanar(width, height uint, img image.Image, interp InterpolationFunction) (r, g, b []uint8) {
+	m := Resize(width, height, img, interp)
+	bounds := m.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	r = make([]uint8, w*h)
+	g = make([]uint8, w*h)
+	b = make([]uint8, w*h)
+
+	if rgba, ok := m.(*image.RGBA); ok {
+		for y := 0; y < h; y++ {
+			i := rgba.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+			for x := 0; x < w; x++ {
+				r[y*w+x] = rgba.Pix[i+0]
+				g[y*w+x] = rgba.Pix[i+1]
+				b[y*w+x] = rgba.Pix[i+2]
+				i += 4
+			}
+		}
+		return r, g, b
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cr, cg, cb, _ := m.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			r[y*w+x] = uint8(cr >> 8)
+			g[y*w+x] = uint8(cg >> 8)
+			b[y*w+x] = uint8(cb >> 8)
+		}
+	}
+	return r, g, b
+}
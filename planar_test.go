@@ -0,0 +1,37 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeToPlanar(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(3, 4, 43, 34))
+	ycc := image.NewYCbCr(image.Rect(0, 0, 40, 30), image.YCbCrSubsampleRatio420)
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			rgba.SetRGBA(x+3, y+4, color.RGBA{uint8(6 * x), uint8(8 * y), uint8(x + y), 0xff})
+			ycc.Y[ycc.YOffset(x, y)] = uint8(6*x + y)
+			ycc.Cb[ycc.COffset(x, y)] = uint8(3 * y)
+			ycc.Cr[ycc.COffset(x, y)] = uint8(4 * x)
+		}
+	}
+
+	for _, img := range []image.Image{rgba, ycc} {
+		r, g, b := ResizeToPlanar(17, 13, img, Bilinear)
+		if len(r) != 17*13 || len(g) != 17*13 || len(b) != 17*13 {
+			t.Fatalf("want planes of %d samples, got %d, %d, %d", 17*13, len(r), len(g), len(b))
+		}
+
+		want := Resize(17, 13, img, Bilinear)
+		for y := 0; y < 13; y++ {
+			for x := 0; x < 17; x++ {
+				got := color.RGBA{r[y*17+x], g[y*17+x], b[y*17+x], 0xff}
+				if c := color.RGBAModel.Convert(want.At(x, y)); c != got {
+					t.Fatalf("%T (%d,%d): want %v, got %v", img, x, y, c, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"sync"
+)
+
+// tempsRGBA64 holds the transposed temporary images of the 16-bit passes,
+// which are discarded after the second pass.
+var tempsRGBA64 sync.Pool
+
+// getTempRGBA64 returns a w x h temporary image. The buffer of a pooled
+// image is reused if it is large enough, otherwise a new one is allocated.
+// The pixels are not cleared: the first pass overwrites all of them.
+func getTempRGBA64(w, h int) *image.RGBA64 {
+	n := 8 * w * h
+	if temp, ok := tempsRGBA64.Get().(*image.RGBA64); ok && cap(temp.Pix) >= n {
+		temp.Pix = temp.Pix[:n]
+		temp.Stride = 8 * w
+		temp.Rect = image.Rect(0, 0, w, h)
+		return temp
+	}
+	return image.NewRGBA64(image.Rect(0, 0, w, h))
+}
+
+// putTempRGBA64 returns a temporary image of getTempRGBA64 to the pool. It
+// must not be used afterwards.
+func putTempRGBA64(temp *image.RGBA64) {
+	tempsRGBA64.Put(temp)
+}
+
+// tempsRGBA and tempsGray hold the temporary images of the 8-bit passes.
+var tempsRGBA, tempsGray sync.Pool
+
+// getTempRGBA is getTempRGBA64 for the 8-bit RGBA passes.
+func getTempRGBA(w, h int) *image.RGBA {
+	n := 4 * w * h
+	if temp, ok := tempsRGBA.Get().(*image.RGBA); ok && cap(temp.Pix) >= n {
+		temp.Pix = temp.Pix[:n]
+		temp.Stride = 4 * w
+		temp.Rect = image.Rect(0, 0, w, h)
+		return temp
+	}
+	return image.NewRGBA(image.Rect(0, 0, w, h))
+}
+
+// putTempRGBA returns a temporary image of getTempRGBA to the pool. It must
+// not be used afterwards.
+func putTempRGBA(temp *image.RGBA) {
+	tempsRGBA.Put(temp)
+}
+
+// getTempGray is getTempRGBA64 for the 8-bit Gray passes.
+func getTempGray(w, h int) *image.Gray {
+	n := w * h
+	if temp, ok := tempsGray.Get().(*image.Gray); ok && cap(temp.Pix) >= n {
+		temp.Pix = temp.Pix[:n]
+		temp.Stride = w
+		temp.Rect = image.Rect(0, 0, w, h)
+		return temp
+	}
+	return image.NewGray(image.Rect(0, 0, w, h))
+}
+
+// putTempGray returns a temporary image of getTempGray to the pool. It must
+// not be used afterwards.
+func putTempGray(temp *image.Gray) {
+	tempsGray.Put(temp)
+}
@@ -0,0 +1,54 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_TempRGBA64NoBleed(t *testing.T) {
+	noise := image.NewRGBA64(image.Rect(0, 0, 200, 150))
+	for i := range noise.Pix {
+		noise.Pix[i] = uint8(i * 7)
+	}
+	flat := image.NewRGBA64(image.Rect(0, 0, 120, 90))
+	c := color.RGBA64{0x1234, 0x2345, 0x3456, 0xffff}
+	for y := 0; y < 90; y++ {
+		for x := 0; x < 120; x++ {
+			flat.SetRGBA64(x, y, c)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		// The pooled buffer of the first resize is larger than the second
+		// one needs and still holds its pixels.
+		Resize(100, 80, noise, Bilinear)
+		m := Resize(60, 40, flat, Bilinear).(*image.RGBA64)
+		for y := 0; y < 40; y++ {
+			for x := 0; x < 60; x++ {
+				if g := m.RGBA64At(x, y); g != c {
+					t.Fatalf("pixel (%d,%d): want %v, got %v", x, y, c, g)
+				}
+			}
+		}
+	}
+
+	temp := getTempRGBA64(30, 20)
+	putTempRGBA64(temp)
+	if temp = getTempRGBA64(10, 5); temp.Bounds() != image.Rect(0, 0, 10, 5) || len(temp.Pix) != 8*10*5 || temp.Stride != 80 {
+		t.Errorf("reused temporary image has bounds %v, stride %d, %d bytes", temp.Bounds(), temp.Stride, len(temp.Pix))
+	}
+}
+
+func Test_Temp8BitReuse(t *testing.T) {
+	rgba := getTempRGBA(30, 20)
+	putTempRGBA(rgba)
+	if rgba = getTempRGBA(10, 5); rgba.Bounds() != image.Rect(0, 0, 10, 5) || len(rgba.Pix) != 4*10*5 || rgba.Stride != 40 {
+		t.Errorf("reused RGBA image has bounds %v, stride %d, %d bytes", rgba.Bounds(), rgba.Stride, len(rgba.Pix))
+	}
+	gray := getTempGray(30, 20)
+	putTempGray(gray)
+	if gray = getTempGray(10, 5); gray.Bounds() != image.Rect(0, 0, 10, 5) || len(gray.Pix) != 10*5 || gray.Stride != 10 {
+		t.Errorf("reused Gray image has bounds %v, stride %d, %d bytes", gray.Bounds(), gray.Stride, len(gray.Pix))
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+)
+
+// Prepare converts img once into a form that Resize processes quickly, for
+// sources that are resized to several sizes.
+func Prepare(img image.Image) image.Image {
+	switch input := img.(type) {
+	case *image.RGBA, *image.NRGBA, *image.YCbCr, *image.RGBA64, *image.NRGBA64, *image.Gray, *image.Gray16,
+		*image.CMYK, *image.Alpha, *image.Alpha16, *image.NYCbCrA:
+		return img
+	case *image.Paletted:
+		return preparePaletted(input)
+	}
+	if img.ColorModel() == color.NRGBAModel {
+		return img
+	}
+
+	b := img.Bounds()
+	out := image.NewRGBA64(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			out.Set(x, y, img.At(x, y))
+		}
+	}
+	return out
+}
+
+// preparePaletted expands img to an *image.RGBA64, converting every palette
+// color only once.
+func preparePaletted(img *image.Paletted) *image.RGBA64 {
+	lut := make([][8]uint8, 256)
+	for i, c := range img.Palette {
+		r, g, b, a := c.RGBA()
+		lut[i] = [8]uint8{
+			uint8(r >> 8), uint8(r),
+			uint8(g >> 8), uint8(g),
+			uint8(b >> 8), uint8(b),
+			uint8(a >> 8), uint8(a),
+		}
+	}
+
+	out := image.NewRGBA64(img.Rect)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		i := img.PixOffset(img.Rect.Min.X, y)
+		o := out.PixOffset(img.Rect.Min.X, y)
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			copy(out.Pix[o:o+8], lut[img.Pix[i]][:])
+			i++
+			o += 8
+		}
+	}
+	return out
+}
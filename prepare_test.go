@@ -0,0 +1,65 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// countingColor counts the calls of its RGBA method.
+type countingColor struct {
+	c     color.RGBA
+	calls *int
+}
+
+func (c countingColor) RGBA() (r, g, b, a uint32) {
+	*c.calls++
+	return c.c.RGBA()
+}
+
+func Test_PreparePaletted(t *testing.T) {
+	var calls int
+	palette := color.Palette{
+		countingColor{color.RGBA{0, 0, 0, 0xff}, &calls},
+		countingColor{color.RGBA{0xff, 0x80, 0, 0xff}, &calls},
+		countingColor{color.RGBA{0, 0x40, 0x80, 0x80}, &calls},
+	}
+	img := image.NewPaletted(image.Rect(2, 3, 42, 33), palette)
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i % 3)
+	}
+
+	sizes := []uint{30, 20, 10}
+	var want []image.Image
+	for _, size := range sizes {
+		want = append(want, Resize(size, 0, img, Bilinear))
+	}
+
+	calls = 0
+	prepared := Prepare(img)
+	for i, size := range sizes {
+		got := Resize(size, 0, prepared, Bilinear)
+		if calls != len(palette) {
+			t.Fatalf("want %d palette conversions, got %d", len(palette), calls)
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("%d: prepared image gives a different result", size)
+		}
+	}
+}
+
+func Test_PrepareUnchanged(t *testing.T) {
+	r := image.Rect(0, 0, 4, 4)
+	for _, img := range []image.Image{
+		image.NewRGBA(r),
+		image.NewCMYK(r),
+		image.NewAlpha(r),
+		image.NewAlpha16(r),
+		image.NewNYCbCrA(r, image.YCbCrSubsampleRatio420),
+	} {
+		if Prepare(img) != img {
+			t.Errorf("specialized image %T was converted", img)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeProgress scales an image like Resize and calls progress with the
+// finished fraction of the work from the goroutine of the caller.
+func ResizeProgress(width, height uint, img image.Image, interp InterpolationFunction, progress func(fraction float64)) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(width), int(height), scaleX, scaleY, interp)
+	p.step = func(pass, done, total int) error {
+		if fraction := (float64(pass) + float64(done)/float64(total)) / 2; fraction > 0 {
+			progress(fraction)
+		}
+		return nil
+	}
+	result := p.apply(img)
+	progress(1)
+	return result
+}
@@ -0,0 +1,36 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func Test_ResizeProgress(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 1000))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+
+	for _, size := range []image.Point{{150, 1200}, {30, 10}} {
+		var fractions []float64
+		m := ResizeProgress(uint(size.X), uint(size.Y), img, Lanczos3, func(fraction float64) {
+			fractions = append(fractions, fraction)
+		})
+		if !bytes.Equal(m.(*image.RGBA).Pix, Resize(uint(size.X), uint(size.Y), img, Lanczos3).(*image.RGBA).Pix) {
+			t.Errorf("%v: result differs from Resize", size)
+		}
+		chunks := (1000+passColumns-1)/passColumns + (size.X+passColumns-1)/passColumns
+		if len(fractions) != chunks {
+			t.Fatalf("%v: want a call per chunk, got %v", size, fractions)
+		}
+		for i, f := range fractions {
+			if f <= 0 || f > 1 || i > 0 && f <= fractions[i-1] {
+				t.Fatalf("%v: fractions do not increase to 1: %v", size, fractions)
+			}
+		}
+		if fractions[len(fractions)-1] != 1 {
+			t.Errorf("%v: want last fraction 1, got %v", size, fractions)
+		}
+	}
+}
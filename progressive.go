@@ -0,0 +1,34 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeProgressive supports viewers that show a quick preview first and
+// replace it with an accurate result later. coarse is scaled right away
+// with NearestNeighbor; refine scales img with Lanczos3 when it is called.
+// Both have the same bounds. The handling of the width and height
+// parameters is the same as in Resize.
+func ResizeProgressive(width, height uint, img image.Image) (coarse image.Image, refine func() image.Image) {
+	coarse = Resize(width, height, img, NearestNeighbor)
+	refine = func() image.Image {
+		return Resize(width, height, img, Lanczos3)
+	}
+	return coarse, refine
+}
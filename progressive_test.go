@@ -0,0 +1,47 @@
+package resize
+
+import (
+	"image"
+	"testing"
+)
+
+// deviation returns the sum of squared differences of the red channel of
+// img from its mean.
+func deviation(img image.Image) float64 {
+	b := img.Bounds()
+	var sum, sum2 float64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, _, _, _ := img.At(x, y).RGBA()
+			sum += float64(r)
+			sum2 += float64(r) * float64(r)
+		}
+	}
+	n := float64(b.Dx() * b.Dy())
+	return sum2 - sum*sum/n
+}
+
+func Test_ResizeProgressive(t *testing.T) {
+	// A fine checkerboard, which should turn into a flat gray.
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if (x+y)%2 == 0 {
+				img.Pix[img.PixOffset(x, y)] = 0xff
+			}
+		}
+	}
+
+	coarse, refine := ResizeProgressive(30, 0, img)
+	want := image.Rect(0, 0, 30, 30)
+	if coarse.Bounds() != want {
+		t.Fatalf("coarse: want bounds %v, got %v", want, coarse.Bounds())
+	}
+	fine := refine()
+	if fine.Bounds() != want {
+		t.Fatalf("refined: want bounds %v, got %v", want, fine.Bounds())
+	}
+	if c, f := deviation(coarse), deviation(fine); f >= c {
+		t.Errorf("refined image aliases as much as the preview: %g >= %g", f, c)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"math"
+)
+
+// ResidualOffset is the value of a residual sample that encodes a
+// difference of zero.
+const ResidualOffset = 0x8000
+
+// ResizeWithResidual scales img like Resize and additionally returns the
+// residual needed to reconstruct img from the scaled image, as used by
+// Laplacian pyramids.
+//
+// The residual is the difference between img and small scaled back to the
+// size of img with the same interpolation function. It has the bounds of img
+// and stores, for every channel of the 16-bit premultiplied colors,
+//   ResidualOffset + (source - upscaled) / 2
+// rounded down. The source is reconstructed as
+//   upscaled + 2 * (residual - ResidualOffset)
+// which is exact to within one 16-bit step. The residual is not a
+// displayable image; its samples should be read with RGBA64At.
+func ResizeWithResidual(width, height uint, img image.Image, interp InterpolationFunction) (small image.Image, residual *image.RGBA64) {
+	b := img.Bounds()
+	small = Resize(width, height, img, interp)
+	up := Resize(uint(b.Dx()), uint(b.Dy()), small, interp)
+	ub := up.Bounds()
+
+	residual = image.NewRGBA64(b)
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			sr, sg, sb, sa := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			ur, ug, ubl, ua := up.At(ub.Min.X+x, ub.Min.Y+y).RGBA()
+			i := residual.PixOffset(b.Min.X+x, b.Min.Y+y)
+			for c, d := range [4]int32{
+				int32(sr) - int32(ur),
+				int32(sg) - int32(ug),
+				int32(sb) - int32(ubl),
+				int32(sa) - int32(ua),
+			} {
+				v := encodeResidual(d)
+				residual.Pix[i+2*c+0] = uint8(v >> 8)
+				residual.Pix[i+2*c+1] = uint8(v)
+			}
+		}
+	}
+	return small, residual
+}
+
+// encodeResidual maps a difference of two 16-bit values to uint16.
+func encodeResidual(d int32) uint16 {
+	return uint16(ResidualOffset + d>>1)
+}
+
+// GaussianPyramid returns octaves slices of scalesPerOctave images of img,
+// blurred progressively from sigma and halved in size per octave.
+func GaussianPyramid(img image.Image, octaves, scalesPerOctave int, sigma float64) [][]image.Image {
+	if octaves <= 0 || scalesPerOctave <= 0 || img.Bounds().Empty() {
+		return nil
+	}
+	k := math.Pow(2, 1/float64(scalesPerOctave))
+
+	base := newFloatImageFromImage(img)
+	// The transposed buffer of the blur passes is shared by all images;
+	// later octaves use a prefix of it.
+	buf := make([]float32, len(base.Pix))
+
+	result := make([][]image.Image, octaves)
+	for o := 0; o < octaves; o++ {
+		w, h := base.Rect.Dx(), base.Rect.Dy()
+		temp := &floatImage{Pix: buf[:len(base.Pix)], Stride: 4 * h, Rect: image.Rect(0, 0, h, w), Channels: 4}
+
+		level := make([]*floatImage, scalesPerOctave)
+		for s := 0; s < scalesPerOctave; s++ {
+			src, sd := base, sigma
+			if s > 0 {
+				// Blurring with a and b adds up to sqrt(a*a + b*b).
+				src = level[s-1]
+				prev := sigma * math.Pow(k, float64(s-1))
+				sd = math.Sqrt(prev*prev*k*k - prev*prev)
+			}
+			if o > 0 && s == 0 {
+				level[s] = src
+				continue
+			}
+			level[s] = newFloatImage(base.Rect, 4)
+			taps, kernel := gaussian(sd)
+			blurFloat(src, level[s], temp, taps, kernel)
+		}
+
+		result[o] = make([]image.Image, scalesPerOctave)
+		for s, l := range level {
+			result[o][s] = l.RGBA64()
+		}
+
+		if o+1 < octaves {
+			last := level[scalesPerOctave-1]
+			nw, nh := (w+1)/2, (h+1)/2
+			base = resizeFloat(last, nw, nh, float64(w)/float64(nw), float64(h)/float64(nh), Bilinear)
+		}
+	}
+	return result
+}
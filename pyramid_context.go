@@ -0,0 +1,88 @@
+// +build go1.7
+
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"context"
+	"image"
+	"runtime"
+	"sync"
+)
+
+// GeneratePyramids scales every image of imgs to every size of sizes using
+// the interpolation function interp. The result holds one slice per image
+// with one entry per size. A size component of 0 is handled like in Resize.
+//
+// At most GOMAXPROCS images are resized concurrently, each by a single
+// goroutine. If ctx is cancelled, the running resizes stop like those of
+// ResizeContext and the partial results are returned together with
+// ctx.Err(); entries that were not finished are nil. If all resizes
+// finished, the error is nil even if ctx was cancelled afterwards.
+func GeneratePyramids(ctx context.Context, imgs []image.Image, sizes []image.Point, interp InterpolationFunction) ([][]image.Image, error) {
+	result := make([][]image.Image, len(imgs))
+	for i := range result {
+		result[i] = make([]image.Image, len(sizes))
+	}
+
+	type job struct{ i, j int }
+	jobs := make(chan job)
+	workers := runtime.GOMAXPROCS(0)
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for jb := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				// The workers already use all CPUs, so every resize is
+				// filtered by a single goroutine. Its temporary images come
+				// from the pools of pool.go, which are shared with all other
+				// resizes.
+				size := sizes[jb.j]
+				if m, err := resizeContext(ctx, 1, uint(size.X), uint(size.Y), imgs[jb.i], interp); err == nil {
+					result[jb.i][jb.j] = m
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range imgs {
+		for j := range sizes {
+			select {
+			case jobs <- job{i, j}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, r := range result {
+		for _, m := range r {
+			if m == nil {
+				return result, ctx.Err()
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+// +build go1.7
+
+package resize
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+)
+
+func Test_GeneratePyramids(t *testing.T) {
+	imgs := []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 64, 48)),
+		image.NewGray(image.Rect(0, 0, 30, 30)),
+	}
+	sizes := []image.Point{{32, 24}, {16, 0}, {0, 5}}
+
+	result, err := GeneratePyramids(context.Background(), imgs, sizes, Bilinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, img := range imgs {
+		for j, size := range sizes {
+			want := Resize(uint(size.X), uint(size.Y), img, Bilinear).Bounds()
+			if result[i][j] == nil || result[i][j].Bounds() != want {
+				t.Errorf("image %d size %v: want bounds %v", i, size, want)
+			}
+		}
+	}
+}
+
+// cancelImage cancels a context as soon as one of its pixels is read.
+type cancelImage struct {
+	*image.Gray
+	once   *sync.Once
+	cancel context.CancelFunc
+}
+
+func (p cancelImage) At(x, y int) color.Color {
+	p.once.Do(p.cancel)
+	return p.Gray.At(x, y)
+}
+
+func Test_GeneratePyramidsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	once := &sync.Once{}
+	imgs := make([]image.Image, 100)
+	for i := range imgs {
+		imgs[i] = cancelImage{image.NewGray(image.Rect(0, 0, 20, 20)), once, cancel}
+	}
+	sizes := []image.Point{{10, 10}, {5, 5}}
+
+	result, err := GeneratePyramids(ctx, imgs, sizes, Bilinear)
+	if err != context.Canceled {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+	if len(result) != len(imgs) {
+		t.Fatalf("want %d results, got %d", len(imgs), len(result))
+	}
+	var done int
+	for _, r := range result {
+		for _, m := range r {
+			if m != nil {
+				done++
+			}
+		}
+	}
+	if done == len(imgs)*len(sizes) {
+		t.Error("all resizes completed after cancellation")
+	}
+}
+
+func Test_GeneratePyramidsCancelAfterLast(t *testing.T) {
+	imgs := []image.Image{image.NewGray(image.Rect(0, 0, 20, 20))}
+	sizes := []image.Point{{10, 10}}
+
+	// Count the checks of a complete run, then cancel right after them.
+	ctx := &lateContext{Context: context.Background(), n: 1 << 30}
+	if _, err := GeneratePyramids(ctx, imgs, sizes, Bilinear); err != nil {
+		t.Fatal(err)
+	}
+	ctx = &lateContext{Context: context.Background(), n: ctx.calls}
+	result, err := GeneratePyramids(ctx, imgs, sizes, Bilinear)
+	if err != nil {
+		t.Fatalf("want nil error after all resizes finished, got %v", err)
+	}
+	if result[0][0] == nil {
+		t.Error("resize is missing")
+	}
+}
+
+func Test_GeneratePyramidsStopsRunningResize(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The only resize cancels ctx while it reads the source.
+	imgs := []image.Image{cancelImage{image.NewGray(image.Rect(0, 0, 200, 200)), &sync.Once{}, cancel}}
+	result, err := GeneratePyramids(ctx, imgs, []image.Point{{10, 10}}, Bilinear)
+	if err != context.Canceled {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+	if result[0][0] != nil {
+		t.Error("cancelled resize was returned")
+	}
+}
@@ -0,0 +1,97 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeWithResidual(t *testing.T) {
+	img := image.NewRGBA(image.Rect(3, 4, 43, 34))
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x * 6), uint8(y * 7), uint8(x * y), 0xff})
+		}
+	}
+
+	small, residual := ResizeWithResidual(20, 15, img, Lanczos3)
+	if small.Bounds() != image.Rect(0, 0, 20, 15) {
+		t.Fatalf("want small bounds %v, got %v", image.Rect(0, 0, 20, 15), small.Bounds())
+	}
+	if residual.Bounds() != img.Bounds() {
+		t.Fatalf("want residual bounds %v, got %v", img.Bounds(), residual.Bounds())
+	}
+
+	up := Resize(40, 30, small, Lanczos3)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			ur, ug, ub, ua := up.At(x-3, y-4).RGBA()
+			d := residual.RGBA64At(x, y)
+			got := [4]int32{
+				int32(ur) + 2*(int32(d.R)-ResidualOffset),
+				int32(ug) + 2*(int32(d.G)-ResidualOffset),
+				int32(ub) + 2*(int32(d.B)-ResidualOffset),
+				int32(ua) + 2*(int32(d.A)-ResidualOffset),
+			}
+			r, g, b, a := img.At(x, y).RGBA()
+			want := [4]int32{int32(r), int32(g), int32(b), int32(a)}
+			for c := range want {
+				if diff := got[c] - want[c]; diff < -1 || diff > 1 {
+					t.Fatalf("(%d,%d) channel %d: reconstructed %d, want %d", x, y, c, got[c], want[c])
+				}
+			}
+		}
+	}
+}
+
+func Test_EncodeResidual(t *testing.T) {
+	var testData = []struct {
+		in       int32
+		expected uint16
+	}{
+		{0, ResidualOffset},
+		{2, ResidualOffset + 1},
+		{-2, ResidualOffset - 1},
+		{65535, 0xffff},
+		{-65535, 0},
+	}
+	for _, test := range testData {
+		if actual := encodeResidual(test.in); actual != test.expected {
+			t.Errorf("encodeResidual(%d) = %d, want %d", test.in, actual, test.expected)
+		}
+	}
+}
+
+func Test_GaussianPyramid(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 64, 48))
+	img.SetGray(32, 24, color.Gray{0xff})
+
+	pyramid := GaussianPyramid(img, 4, 3, 1.6)
+	if len(pyramid) != 4 {
+		t.Fatalf("want 4 octaves, got %d", len(pyramid))
+	}
+	var n int
+	size := image.Pt(64, 48)
+	for o, octave := range pyramid {
+		n += len(octave)
+		for s, m := range octave {
+			if m.Bounds().Size() != size {
+				t.Errorf("octave %d scale %d: want size %v, got %v", o, s, size, m.Bounds().Size())
+			}
+		}
+		size = image.Pt((size.X+1)/2, (size.Y+1)/2)
+	}
+	if n != 4*3 {
+		t.Errorf("want %d images, got %d", 4*3, n)
+	}
+
+	// The peak is spread further with every scale.
+	var last uint32 = 0xffff
+	for s, m := range pyramid[0] {
+		r, _, _, _ := m.At(32, 24).RGBA()
+		if r >= last {
+			t.Errorf("scale %d: peak %d did not decrease from %d", s, r, last)
+		}
+		last = r
+	}
+}
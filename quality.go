@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"sync"
+)
+
+// QualityMetrics describes artifacts of a resize, as returned by
+// ResizeWithQuality. Values are relative to the full range of a sample.
+type QualityMetrics struct {
+	// Overshoot is the largest amount by which a sample of either pass
+	// lies outside of the range of the source samples it is computed
+	// from. Kernels with negative lobes overshoot at edges, which shows
+	// as ringing; kernels without them have an overshoot of 0.
+	Overshoot float64
+	// HighFrequencyEnergy is the mean squared difference of horizontally
+	// and vertically adjacent samples of the result. High values after a
+	// reduction indicate aliasing.
+	HighFrequencyEnergy float64
+}
+
+// ResizeWithQuality scales an image like Resize and reports metrics of the
+// artifacts in the result, e.g. to flag a filter that rings badly. The
+// image is processed with float precision and the result is an
+// *image.RGBA64; the overshoot is tracked during the passes.
+func ResizeWithQuality(width, height uint, img image.Image, interp InterpolationFunction) (image.Image, QualityMetrics) {
+	var q QualityMetrics
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+	if (int(width) == b.Dx() && int(height) == b.Dy()) || b.Dx() <= 0 || b.Dy() <= 0 {
+		return img, q
+	}
+
+	in := newFloatImageFromImage(img)
+	temp := newFloatImage(image.Rect(0, 0, in.Rect.Dy(), int(width)), 4)
+	out := newFloatImage(image.Rect(0, 0, int(width), int(height)), 4)
+
+	var mu sync.Mutex
+	track := func(src, dst *floatImage, rows int, coeffs []float32, index []int, filterLength int) {
+		parallelRows(rows, func(y0, y1 int) {
+			convolveFloat(src, dst, y0, y1, coeffs, index, filterLength)
+			o := float64(overshootFloat(src, dst, y0, y1, coeffs, index, filterLength))
+			mu.Lock()
+			if o > q.Overshoot {
+				q.Overshoot = o
+			}
+			mu.Unlock()
+		})
+	}
+
+	coeffs, offset, filterLength := createWeightsInterp(int(width), interp, scaleX, 0, 0)
+	index := borderIndex(coeffs, offset, filterLength, in.Rect.Dx(), Replicate)
+	track(in, temp, int(width), coeffs, index, filterLength)
+
+	coeffs, offset, filterLength = createWeightsInterp(int(height), interp, scaleY, 0, 0)
+	index = borderIndex(coeffs, offset, filterLength, temp.Rect.Dx(), Replicate)
+	track(temp, out, int(height), coeffs, index, filterLength)
+
+	q.Overshoot /= 0xffff
+	q.HighFrequencyEnergy = highFrequencyEnergyFloat(out)
+	return out.RGBA64(), q
+}
+
+// overshootFloat returns the largest distance of the samples in the rows
+// [y0,y1) of out, as computed by convolveFloat, from the range of the
+// samples of their taps.
+func overshootFloat(in, out *floatImage, y0, y1 int, coeffs []float32, index []int, filterLength int) float32 {
+	n := in.Channels
+	var overshoot float32
+	for x := 0; x < out.Rect.Dx(); x++ {
+		row := in.Pix[x*in.Stride:]
+		for y := y0; y < y1; y++ {
+			xo := y*out.Stride + x*n
+			ci := y * filterLength
+			for c := 0; c < n; c++ {
+				first := true
+				var min, max float32
+				for i := 0; i < filterLength; i++ {
+					if coeffs[ci+i] == 0 {
+						continue
+					}
+					v := row[index[ci+i]*n+c]
+					if first || v < min {
+						min = v
+					}
+					if first || v > max {
+						max = v
+					}
+					first = false
+				}
+				if v := out.Pix[xo+c]; v-max > overshoot {
+					overshoot = v - max
+				} else if min-v > overshoot {
+					overshoot = min - v
+				}
+			}
+		}
+	}
+	return overshoot
+}
+
+// highFrequencyEnergyFloat returns the mean squared difference of adjacent
+// samples of p, relative to the 16-bit range.
+func highFrequencyEnergyFloat(p *floatImage) float64 {
+	var sum float64
+	var count int
+	n := p.Channels
+	for y := 0; y < p.Rect.Dy(); y++ {
+		for x := 0; x < p.Rect.Dx(); x++ {
+			i := p.PixOffset(x, y)
+			for c := 0; c < n; c++ {
+				v := float64(p.Pix[i+c]) / 0xffff
+				if x+1 < p.Rect.Dx() {
+					d := v - float64(p.Pix[i+n+c])/0xffff
+					sum += d * d
+					count++
+				}
+				if y+1 < p.Rect.Dy() {
+					d := v - float64(p.Pix[i+p.Stride+c])/0xffff
+					sum += d * d
+					count++
+				}
+			}
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
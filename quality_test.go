@@ -0,0 +1,47 @@
+package resize
+
+import (
+	"image"
+	"testing"
+)
+
+func Test_ResizeWithQualityOvershoot(t *testing.T) {
+	step := image.NewGray(image.Rect(0, 0, 40, 8))
+	for y := 0; y < 8; y++ {
+		for x := 20; x < 40; x++ {
+			step.Pix[step.PixOffset(x, y)] = 0xc0
+		}
+	}
+
+	_, bilinear := ResizeWithQuality(100, 8, step, Bilinear)
+	_, lanczos := ResizeWithQuality(100, 8, step, Lanczos3)
+	if bilinear.Overshoot > 1e-6 {
+		t.Errorf("Bilinear overshoots by %g", bilinear.Overshoot)
+	}
+	if lanczos.Overshoot <= 0.01 {
+		t.Errorf("Lanczos3 overshoot %g is not detected", lanczos.Overshoot)
+	}
+}
+
+func Test_ResizeWithQualityAliasing(t *testing.T) {
+	checker := image.NewGray(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if (x+y)%2 == 0 {
+				checker.Pix[checker.PixOffset(x, y)] = 0xff
+			}
+		}
+	}
+
+	m, q := ResizeWithQuality(32, 32, checker, Bilinear)
+	if m.Bounds() != image.Rect(0, 0, 32, 32) {
+		t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 32, 32), m.Bounds())
+	}
+	_, flat := ResizeWithQuality(32, 32, image.NewGray(image.Rect(0, 0, 64, 64)), Bilinear)
+	if flat.HighFrequencyEnergy != 0 {
+		t.Errorf("flat image has energy %g", flat.HighFrequencyEnergy)
+	}
+	if q.HighFrequencyEnergy < 0 || q.HighFrequencyEnergy > 1 {
+		t.Errorf("energy %g out of range", q.HighFrequencyEnergy)
+	}
+}
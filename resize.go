@@ -26,8 +26,7 @@ package resize
 
 import (
 	"image"
-	"runtime"
-	"sync"
+	"image/color"
 )
 
 // An InterpolationFunction provides the parameters that describe an
@@ -49,10 +48,79 @@ const (
 	Lanczos2
 	// Lanczos interpolation (a=3)
 	Lanczos3
+	// Area averaging: a box kernel that averages the source pixels under an
+	// output pixel when reducing and picks the nearest pixel when enlarging
+	Area
 )
 
+// lanczosBase is added to a to encode the interpolation functions returned
+// by Lanczos.
+const lanczosBase InterpolationFunction = 1 << 16
+
+// maxLanczos is the largest number of lobes of Lanczos.
+const maxLanczos = 64
+
+// Lanczos returns a Lanczos interpolation with a lobes, e.g. 4 for a
+// sharper kernel than Lanczos3. Lanczos(2) and Lanczos(3) are Lanczos2 and
+// Lanczos3; an a of 0 gives NearestNeighbor and a is limited to 64.
+func Lanczos(a uint) InterpolationFunction {
+	if a > maxLanczos {
+		a = maxLanczos
+	}
+	switch a {
+	case 0:
+		return NearestNeighbor
+	case 2:
+		return Lanczos2
+	case 3:
+		return Lanczos3
+	}
+	return lanczosBase + InterpolationFunction(a)
+}
+
+// cubicBase is added to the encoded parameters to encode the interpolation
+// functions returned by CubicBC.
+const cubicBase InterpolationFunction = 1 << 17
+
+// cubicSteps is the number of steps the parameters of CubicBC are rounded
+// to between 0 and 1. It is divisible by 3, so that 1/3 is exact.
+const cubicSteps = 3600
+
+// CubicBC returns a cubic interpolation from the family of Mitchell and
+// Netravali with the parameters b and c. b blurs and c sharpens:
+// CubicBC(1.0/3, 1.0/3) is MitchellNetravali, CubicBC(0, 0.5) is
+// Catmull-Rom like Bicubic and CubicBC(1, 0) is the cubic B-spline, which
+// does not ring but blurs. Good tradeoffs are on the line b+2c = 1 with b
+// in [0,1]; larger values of c ring more, larger values of b blur more.
+// b and c are clamped to [0,1] and rounded to multiples of 1/3600. The
+// parameters are encoded in the returned value, so no state is kept.
+func CubicBC(b, c float32) InterpolationFunction {
+	return cubicBase + InterpolationFunction(cubicStep(b)*(cubicSteps+1)+cubicStep(c))
+}
+
+// cubicStep clamps x to [0,1] and returns it in steps of 1/cubicSteps.
+// NaN becomes 0.
+func cubicStep(x float32) int {
+	switch {
+	case x >= 1:
+		return cubicSteps
+	case x > 0:
+		return int(float64(x)*cubicSteps + 0.5)
+	}
+	return 0
+}
+
 // kernal, returns an InterpolationFunctions taps and kernel.
 func (i InterpolationFunction) kernel() (int, func(float64) float64) {
+	if i >= cubicBase {
+		n := int(i - cubicBase)
+		b, c := n/(cubicSteps+1), n%(cubicSteps+1)
+		return 4, splineKernel(float64(b)/cubicSteps, float64(c)/cubicSteps)
+	}
+	if i > lanczosBase && i <= lanczosBase+maxLanczos {
+		a := int(i - lanczosBase)
+		return 2 * a, lanczosKernel(a)
+	}
 	switch i {
 	case Bilinear:
 		return 2, linear
@@ -64,30 +132,83 @@ func (i InterpolationFunction) kernel() (int, func(float64) float64) {
 		return 4, lanczos2
 	case Lanczos3:
 		return 6, lanczos3
+	case Area:
+		return 2, box
 	default:
 		// Default to NearestNeighbor.
 		return 2, nearest
 	}
 }
 
+// valid reports whether i is one of the constants or was returned by
+// Lanczos or CubicBC.
+func (i InterpolationFunction) valid() bool {
+	switch {
+	case i >= cubicBase:
+		return int(i-cubicBase) < (cubicSteps+1)*(cubicSteps+1)
+	case i > lanczosBase:
+		return i <= lanczosBase+maxLanczos
+	}
+	return i >= NearestNeighbor && i <= Area
+}
+
 // values <1 will sharpen the image
 var blur = 1.0
 
 // Resize scales an image to new width and height using the interpolation function interp.
 // A new image with the given dimensions will be returned.
 // If one of the parameters width or height is set to 0, its size will be calculated so that
-// the aspect ratio is that of the originating image.
+// the aspect ratio is that of the originating image. The calculated size is rounded up
+// from a fractional part of 0.3, so it may differ by one pixel from the exact ratio.
+// Non-zero values of width and height are always used exactly, by both passes.
 // The resizing algorithm uses channels for parallel computation.
+// Pixel centers are aligned, i.e. the center of the source pixel x is mapped
+// to (x+0.5)*width/oldWidth-0.5 in the result, and likewise for y.
 // If the input image has width or height of 0, it is returned unchanged.
+// The type of the result depends on that of img: *image.RGBA, *image.NRGBA
+// and *image.CMYK give an *image.RGBA, *image.YCbCr gives one with 4:4:4
+// chroma, the gray and alpha types keep their type, other images with the
+// color model color.NRGBAModel give an *image.NRGBA64 and all others an
+// *image.RGBA64. With NearestNeighbor, *image.NRGBA and *image.NRGBA64
+// keep their type and *image.CMYK gives an *image.RGBA64.
 func Resize(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
-	scaleX, scaleY := calcFactors(width, height, float64(img.Bounds().Dx()), float64(img.Bounds().Dy()))
-	if width == 0 {
-		width = uint(0.7 + float64(img.Bounds().Dx())/scaleX)
+	width, height, scaleX, scaleY := scaledSize(width, height, img.Bounds().Dx(), img.Bounds().Dy())
+
+	// Trivial case: return input image
+	if int(width) == img.Bounds().Dx() && int(height) == img.Bounds().Dy() {
+		return img
 	}
-	if height == 0 {
-		height = uint(0.7 + float64(img.Bounds().Dy())/scaleY)
+
+	// Input image has no pixels
+	if img.Bounds().Dx() <= 0 || img.Bounds().Dy() <= 0 {
+		return img
+	}
+
+	return newPlan(img.Bounds().Dx(), img.Bounds().Dy(), int(width), int(height), scaleX, scaleY, interp).apply(img)
+}
+
+// OutputSize returns the size of the image that Resize returns for the
+// given width, height and img, without resizing it. A width or height of 0
+// is computed from the aspect ratio with the same rounding as in Resize.
+func OutputSize(width, height uint, img image.Image) (w, h int) {
+	b := img.Bounds()
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return b.Dx(), b.Dy()
 	}
 
+	width, height, _, _ = scaledSize(width, height, b.Dx(), b.Dy())
+	return int(width), int(height)
+}
+
+// ResizeParallel scales an image like Resize, but filters each pass with
+// at most workers goroutines instead of GOMAXPROCS, e.g. to share the CPUs
+// of a server among many concurrent resizes. The number is also limited by
+// the size of the result. The result is the same as that of Resize for any
+// number of workers; workers <= 0 selects GOMAXPROCS.
+func ResizeParallel(workers int, width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	width, height, scaleX, scaleY := scaledSize(width, height, img.Bounds().Dx(), img.Bounds().Dy())
+
 	// Trivial case: return input image
 	if int(width) == img.Bounds().Dx() && int(height) == img.Bounds().Dy() {
 		return img
@@ -98,74 +219,102 @@ func Resize(width, height uint, img image.Image, interp InterpolationFunction) i
 		return img
 	}
 
-	if interp == NearestNeighbor {
-		return resizeNearest(width, height, scaleX, scaleY, img, interp)
+	p := newPlan(img.Bounds().Dx(), img.Bounds().Dy(), int(width), int(height), scaleX, scaleY, interp)
+	p.workers = workers
+	return p.apply(img)
+}
+
+// apply scales img, whose size must match the source size of p, with the
+// weights of p.
+// The first pass filters the rows of img and writes them as columns of a
+// transposed temporary image, oldHeight pixels wide and width pixels high,
+// so that the second pass can filter rows again and transpose them back.
+// ResizeStaged and ResizeStagedUpright return this intermediate.
+func (p *ResizePlan) apply(img image.Image) image.Image {
+	width, height := uint(p.width), uint(p.height)
+	scaleX, scaleY := p.scaleX, p.scaleY
+
+	// Alpha masks are resized as gray images, which have the same layout,
+	// so that the result is a mask again.
+	switch input := img.(type) {
+	case *image.Alpha:
+		m := p.apply(&image.Gray{Pix: input.Pix, Stride: input.Stride, Rect: input.Rect}).(*image.Gray)
+		return &image.Alpha{Pix: m.Pix, Stride: m.Stride, Rect: m.Rect}
+	case *image.Alpha16:
+		m := p.apply(&image.Gray16{Pix: input.Pix, Stride: input.Stride, Rect: input.Rect}).(*image.Gray16)
+		return &image.Alpha16{Pix: m.Pix, Stride: m.Stride, Rect: m.Rect}
 	}
 
-	taps, kernel := interp.kernel()
-	cpus := runtime.GOMAXPROCS(0)
-	wg := sync.WaitGroup{}
+	if p.interp == NearestNeighbor {
+		return resizeNearest(width, height, scaleX, scaleY, img, p)
+	}
+	if m := p.applyOneAxis(img); m != nil {
+		return m
+	}
 
 	// Generic access to image.Image is slow in tight loops.
 	// The optimal access has to be determined from the concrete image type.
 	switch input := img.(type) {
 	case *image.RGBA:
 		// 8-bit precision
-		temp := image.NewRGBA(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempRGBA(input.Bounds().Dy(), int(width))
+		defer putTempRGBA(temp)
 		result := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights8(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA)
-			go func() {
-				defer wg.Done()
-				resizeRGBA(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights8X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			resizeRGBA(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights8(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA)
-			go func() {
-				defer wg.Done()
-				resizeRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights8Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			resizeRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.NRGBA:
 		// 8-bit precision
-		temp := image.NewRGBA(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempRGBA(input.Bounds().Dy(), int(width))
+		defer putTempRGBA(temp)
 		result := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights8(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA)
-			go func() {
-				defer wg.Done()
-				resizeNRGBA(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights8X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			resizeNRGBA(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights8(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA)
-			go func() {
-				defer wg.Done()
-				resizeRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights8Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			resizeRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
+		return result
+
+	case *image.CMYK:
+		// 8-bit precision
+		temp := getTempRGBA(input.Bounds().Dy(), int(width))
+		defer putTempRGBA(temp)
+		result := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+
+		// horizontal filter, results in transposed temporary image
+		coeffs, offset, filterLength := p.weights8X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			resizeCMYK(input, slice, scaleX, coeffs, offset, filterLength)
+		})
+
+		// horizontal filter on transposed image, result is not transposed
+		coeffs, offset, filterLength = p.weights8Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			resizeRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 
 	case *image.YCbCr:
@@ -175,115 +324,119 @@ func Resize(width, height uint, img image.Image, interp InterpolationFunction) i
 		temp := newYCC(image.Rect(0, 0, input.Bounds().Dy(), int(width)), input.SubsampleRatio)
 		result := newYCC(image.Rect(0, 0, int(width), int(height)), image.YCbCrSubsampleRatio444)
 
-		coeffs, offset, filterLength := createWeights8(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
+		coeffs, offset, filterLength := p.weights8X()
 		in := imageYCbCrToYCC(input)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*ycc)
-			go func() {
-				defer wg.Done()
-				resizeYCbCr(in, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
-
-		coeffs, offset, filterLength = createWeights8(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*ycc)
-			go func() {
-				defer wg.Done()
-				resizeYCbCr(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*ycc)
+			resizeYCbCr(in, slice, scaleX, coeffs, offset, filterLength)
+		})
+
+		coeffs, offset, filterLength = p.weights8Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*ycc)
+			resizeYCbCr(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result.YCbCr()
 	case *image.RGBA64:
 		// 16-bit precision
-		temp := image.NewRGBA64(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempRGBA64(input.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
 		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights16(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				resizeRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights16X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights16(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights16Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.NRGBA64:
 		// 16-bit precision
-		temp := image.NewRGBA64(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempRGBA64(input.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
 		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights16(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				resizeNRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights16X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeNRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights16(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights16Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
+		return result
+	case *image.NYCbCrA:
+		// 16-bit precision
+		temp := getTempRGBA64(input.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
+		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
+
+		// horizontal filter, results in transposed temporary image
+		coeffs, offset, filterLength := p.weights16X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeNYCbCrA(input, slice, scaleX, coeffs, offset, filterLength)
+		})
+
+		// horizontal filter on transposed image, result is not transposed
+		coeffs, offset, filterLength = p.weights16Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
+		return result
+	case *image.Paletted:
+		// 16-bit precision
+		temp := getTempRGBA64(input.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
+		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
+		colors := paletteRGBA64(input.Palette)
+
+		// horizontal filter, results in transposed temporary image
+		coeffs, offset, filterLength := p.weights16X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizePaletted(input, slice, scaleX, colors, coeffs, offset, filterLength)
+		})
+
+		// horizontal filter on transposed image, result is not transposed
+		coeffs, offset, filterLength = p.weights16Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.Gray:
 		// 8-bit precision
-		temp := image.NewGray(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempGray(input.Bounds().Dy(), int(width))
+		defer putTempGray(temp)
 		result := image.NewGray(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights8(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.Gray)
-			go func() {
-				defer wg.Done()
-				resizeGray(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights8X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.Gray)
+			resizeGray(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights8(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.Gray)
-			go func() {
-				defer wg.Done()
-				resizeGray(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights8Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.Gray)
+			resizeGray(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.Gray16:
 		// 16-bit precision
@@ -291,95 +444,78 @@ func Resize(width, height uint, img image.Image, interp InterpolationFunction) i
 		result := image.NewGray16(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights16(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.Gray16)
-			go func() {
-				defer wg.Done()
-				resizeGray16(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights16X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.Gray16)
+			resizeGray16(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights16(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.Gray16)
-			go func() {
-				defer wg.Done()
-				resizeGray16(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights16Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.Gray16)
+			resizeGray16(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	default:
+		if img.ColorModel() == color.NRGBAModel {
+			// 16-bit precision, straight alpha avoids the
+			// premultiplication round trip of At().RGBA()
+			temp := image.NewNRGBA64(image.Rect(0, 0, img.Bounds().Dy(), int(width)))
+			result := image.NewNRGBA64(image.Rect(0, 0, int(width), int(height)))
+
+			// horizontal filter, results in transposed temporary image
+			coeffs, offset, filterLength := p.weights16X()
+			p.runPass(0, temp, func(s image.Image) {
+				slice := s.(*image.NRGBA64)
+				resizeGenericNRGBA(img, slice, scaleX, coeffs, offset, filterLength)
+			})
+
+			// horizontal filter on transposed image, result is not transposed
+			coeffs, offset, filterLength = p.weights16Y()
+			p.runPass(1, result, func(s image.Image) {
+				slice := s.(*image.NRGBA64)
+				resizeNRGBA64Straight(temp, slice, scaleY, coeffs, offset, filterLength)
+			})
+			return result
+		}
+
 		// 16-bit precision
-		temp := image.NewRGBA64(image.Rect(0, 0, img.Bounds().Dy(), int(width)))
+		temp := getTempRGBA64(img.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
 		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeights16(temp.Bounds().Dy(), taps, blur, scaleX, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				resizeGeneric(img, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weights16X()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeGeneric(img, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeights16(result.Bounds().Dy(), taps, blur, scaleY, kernel)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weights16Y()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	}
 }
 
-func resizeNearest(width, height uint, scaleX, scaleY float64, img image.Image, interp InterpolationFunction) image.Image {
-	taps, _ := interp.kernel()
-	cpus := runtime.GOMAXPROCS(0)
-	wg := sync.WaitGroup{}
-
+func resizeNearest(width, height uint, scaleX, scaleY float64, img image.Image, p *ResizePlan) image.Image {
 	switch input := img.(type) {
 	case *image.RGBA:
-		// 8-bit precision
-		temp := image.NewRGBA(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		// 8-bit precision, in a single pass
 		result := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
 
-		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA)
-			go func() {
-				defer wg.Done()
-				nearestRGBA(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
-
-		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA)
-			go func() {
-				defer wg.Done()
-				nearestRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		xIdx, xFirst := nearestTaps(coeffs, offset, filterLength, input.Bounds().Dx()-1)
+		coeffs, offset, filterLength = p.weightsNearestY()
+		yIdx, yFirst := nearestTaps(coeffs, offset, filterLength, input.Bounds().Dy()-1)
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA)
+			nearestRGBA(input, slice, xIdx, xFirst, yIdx, yFirst)
+		})
 		return result
 	case *image.NRGBA:
 		// 8-bit precision
@@ -387,28 +523,18 @@ func resizeNearest(width, height uint, scaleX, scaleY float64, img image.Image,
 		result := image.NewNRGBA(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.NRGBA)
-			go func() {
-				defer wg.Done()
-				nearestNRGBA(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.NRGBA)
+			nearestNRGBA(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.NRGBA)
-			go func() {
-				defer wg.Done()
-				nearestNRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.NRGBA)
+			nearestNRGBA(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.YCbCr:
 		// 8-bit precision
@@ -417,57 +543,38 @@ func resizeNearest(width, height uint, scaleX, scaleY float64, img image.Image,
 		temp := newYCC(image.Rect(0, 0, input.Bounds().Dy(), int(width)), input.SubsampleRatio)
 		result := newYCC(image.Rect(0, 0, int(width), int(height)), image.YCbCrSubsampleRatio444)
 
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
+		coeffs, offset, filterLength := p.weightsNearestX()
 		in := imageYCbCrToYCC(input)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*ycc)
-			go func() {
-				defer wg.Done()
-				nearestYCbCr(in, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
-
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*ycc)
-			go func() {
-				defer wg.Done()
-				nearestYCbCr(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*ycc)
+			nearestYCbCr(in, slice, scaleX, coeffs, offset, filterLength)
+		})
+
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*ycc)
+			nearestYCbCr(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result.YCbCr()
 	case *image.RGBA64:
 		// 16-bit precision
-		temp := image.NewRGBA64(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempRGBA64(input.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
 		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				nearestRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			nearestRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				nearestRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			nearestRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.NRGBA64:
 		// 16-bit precision
@@ -475,57 +582,38 @@ func resizeNearest(width, height uint, scaleX, scaleY float64, img image.Image,
 		result := image.NewNRGBA64(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.NRGBA64)
-			go func() {
-				defer wg.Done()
-				nearestNRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.NRGBA64)
+			nearestNRGBA64(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.NRGBA64)
-			go func() {
-				defer wg.Done()
-				nearestNRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.NRGBA64)
+			nearestNRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.Gray:
 		// 8-bit precision
-		temp := image.NewGray(image.Rect(0, 0, input.Bounds().Dy(), int(width)))
+		temp := getTempGray(input.Bounds().Dy(), int(width))
+		defer putTempGray(temp)
 		result := image.NewGray(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.Gray)
-			go func() {
-				defer wg.Done()
-				nearestGray(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.Gray)
+			nearestGray(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.Gray)
-			go func() {
-				defer wg.Done()
-				nearestGray(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.Gray)
+			nearestGray(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	case *image.Gray16:
 		// 16-bit precision
@@ -533,62 +621,84 @@ func resizeNearest(width, height uint, scaleX, scaleY float64, img image.Image,
 		result := image.NewGray16(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.Gray16)
-			go func() {
-				defer wg.Done()
-				nearestGray16(input, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.Gray16)
+			nearestGray16(input, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.Gray16)
-			go func() {
-				defer wg.Done()
-				nearestGray16(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.Gray16)
+			nearestGray16(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	default:
 		// 16-bit precision
-		temp := image.NewRGBA64(image.Rect(0, 0, img.Bounds().Dy(), int(width)))
+		temp := getTempRGBA64(img.Bounds().Dy(), int(width))
+		defer putTempRGBA64(temp)
 		result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
 
 		// horizontal filter, results in transposed temporary image
-		coeffs, offset, filterLength := createWeightsNearest(temp.Bounds().Dy(), taps, blur, scaleX)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(temp, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				nearestGeneric(img, slice, scaleX, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			nearestGeneric(img, slice, scaleX, coeffs, offset, filterLength)
+		})
 
 		// horizontal filter on transposed image, result is not transposed
-		coeffs, offset, filterLength = createWeightsNearest(result.Bounds().Dy(), taps, blur, scaleY)
-		wg.Add(cpus)
-		for i := 0; i < cpus; i++ {
-			slice := makeSlice(result, i, cpus).(*image.RGBA64)
-			go func() {
-				defer wg.Done()
-				nearestRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
-			}()
-		}
-		wg.Wait()
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			nearestRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
 		return result
 	}
 
 }
 
+// ResizeYCbCrSeparate scales a YCbCr image like Resize, but filters the luma
+// plane with lumaInterp and the chroma planes with chromaInterp. As chroma
+// detail is less visible, a cheaper chromaInterp saves time with little
+// visible loss.
+func ResizeYCbCrSeparate(width, height uint, img *image.YCbCr, lumaInterp, chromaInterp InterpolationFunction) *image.YCbCr {
+	width, height, scaleX, scaleY := scaledSize(width, height, img.Bounds().Dx(), img.Bounds().Dy())
+
+	// Trivial case: return input image
+	if int(width) == img.Bounds().Dx() && int(height) == img.Bounds().Dy() {
+		return img
+	}
+
+	// Input image has no pixels
+	if img.Bounds().Dx() <= 0 || img.Bounds().Dy() <= 0 {
+		return img
+	}
+
+	luma := newPlan(img.Bounds().Dx(), img.Bounds().Dy(), int(width), int(height), scaleX, scaleY, lumaInterp)
+	chroma := newPlan(img.Bounds().Dx(), img.Bounds().Dy(), int(width), int(height), scaleX, scaleY, chromaInterp)
+
+	// 8-bit precision
+	temp := newYCC(image.Rect(0, 0, img.Bounds().Dy(), int(width)), img.SubsampleRatio)
+	result := newYCC(image.Rect(0, 0, int(width), int(height)), image.YCbCrSubsampleRatio444)
+
+	lumaCoeffs, lumaOffset, lumaLength := luma.weights8X()
+	chromaCoeffs, chromaOffset, chromaLength := chroma.weights8X()
+	in := imageYCbCrToYCC(img)
+	luma.runPass(0, temp, func(s image.Image) {
+		slice := s.(*ycc)
+		resizeYCbCrSeparate(in, slice, scaleX, lumaCoeffs, lumaOffset, lumaLength, chromaCoeffs, chromaOffset, chromaLength)
+	})
+
+	lumaCoeffs, lumaOffset, lumaLength = luma.weights8Y()
+	chromaCoeffs, chromaOffset, chromaLength = chroma.weights8Y()
+	luma.runPass(1, result, func(s image.Image) {
+		slice := s.(*ycc)
+		resizeYCbCrSeparate(temp, slice, scaleY, lumaCoeffs, lumaOffset, lumaLength, chromaCoeffs, chromaOffset, chromaLength)
+	})
+	return result.YCbCr()
+}
+
 // Calculates scaling factors using old and new image dimensions.
 func calcFactors(width, height uint, oldWidth, oldHeight float64) (scaleX, scaleY float64) {
 	if width == 0 {
@@ -610,6 +720,20 @@ func calcFactors(width, height uint, oldWidth, oldHeight float64) (scaleX, scale
 	return
 }
 
+// scaledSize returns the size of the result of Resize for a source of
+// srcWidth x srcHeight pixels, where a width or height of 0 is computed
+// from the aspect ratio, together with the scaling factors.
+func scaledSize(width, height uint, srcWidth, srcHeight int) (uint, uint, float64, float64) {
+	scaleX, scaleY := calcFactors(width, height, float64(srcWidth), float64(srcHeight))
+	if width == 0 {
+		width = uint(0.7 + float64(srcWidth)/scaleX)
+	}
+	if height == 0 {
+		height = uint(0.7 + float64(srcHeight)/scaleY)
+	}
+	return width, height, scaleX, scaleY
+}
+
 type imageWithSubImage interface {
 	image.Image
 	SubImage(image.Rectangle) image.Image
@@ -0,0 +1,66 @@
+// +build go1.7
+
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"context"
+	"image"
+)
+
+// ResizeContext scales an image like Resize, but can be cancelled: both
+// filter passes are computed in chunks of passColumns columns of their
+// output, which are source rows in the first pass, and ctx is checked
+// before every chunk. If it is cancelled, the rest of the passes is skipped
+// and the partially computed image is returned together with ctx.Err();
+// pixels that were not computed are zero. Otherwise the result is the same
+// as that of Resize.
+func ResizeContext(ctx context.Context, width, height uint, img image.Image, interp InterpolationFunction) (image.Image, error) {
+	return resizeContext(ctx, 0, width, height, img, interp)
+}
+
+// resizeContext is ResizeContext with at most workers goroutines per pass,
+// as in ResizeParallel.
+func resizeContext(ctx context.Context, workers int, width, height uint, img image.Image, interp InterpolationFunction) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img, nil
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img, nil
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(width), int(height), scaleX, scaleY, interp)
+	p.workers = workers
+	var err error
+	p.step = func(pass, done, total int) error {
+		err = ctx.Err()
+		return err
+	}
+	result := p.apply(img)
+	return result, err
+}
@@ -0,0 +1,119 @@
+// +build go1.7
+
+package resize
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_ResizeContext(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 90, 170))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	ycc := image.NewYCbCr(image.Rect(0, 0, 64, 150), image.YCbCrSubsampleRatio420)
+	for i := range ycc.Y {
+		ycc.Y[i] = uint8(i * 3)
+	}
+	for i := range ycc.Cb {
+		ycc.Cb[i], ycc.Cr[i] = uint8(i*5), uint8(i*11)
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 90, 170))
+	copy(nrgba.Pix, rgba.Pix)
+	gray16 := image.NewGray16(image.Rect(0, 0, 90, 170))
+	copy(gray16.Pix, rgba.Pix)
+	cmyk := image.NewCMYK(image.Rect(0, 0, 90, 170))
+	copy(cmyk.Pix, rgba.Pix)
+
+	for _, img := range []image.Image{rgba, ycc, nrgba, gray16, cmyk} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Lanczos3} {
+			for _, size := range []image.Point{{30, 20}, {130, 300}} {
+				m, err := ResizeContext(context.Background(), uint(size.X), uint(size.Y), img, interp)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := Resize(uint(size.X), uint(size.Y), img, interp)
+				if m.Bounds() != want.Bounds() {
+					t.Fatalf("%T: want bounds %v, got %v", img, want.Bounds(), m.Bounds())
+				}
+				for y := 0; y < size.Y; y++ {
+					for x := 0; x < size.X; x++ {
+						if g, w := color.RGBA64Model.Convert(m.At(x, y)), color.RGBA64Model.Convert(want.At(x, y)); g != w {
+							t.Fatalf("%T %v %v: pixel (%d,%d): want %v, got %v", img, size, interp, x, y, w, g)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ResizeContext(ctx, 30, 20, rgba, Bilinear); err != context.Canceled {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+}
+
+// lateContext is cancelled from the call of Err after the first n on.
+type lateContext struct {
+	context.Context
+	calls, n int32
+}
+
+func (c *lateContext) Err() error {
+	if atomic.AddInt32(&c.calls, 1) > c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func Test_ResizeContextCancel(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 1000, 1000))
+	for _, size := range []image.Point{{2000, 2000}, {64, 64}} {
+		// ctx is checked on entry and before every chunk; it is cancelled
+		// before the fourth chunk of the first pass.
+		ctx := &lateContext{Context: context.Background(), n: 4}
+		m, err := ResizeContext(ctx, uint(size.X), uint(size.Y), img, Lanczos3)
+		if err != context.Canceled {
+			t.Fatalf("%v: want %v, got %v", size, context.Canceled, err)
+		}
+		// One failed check stops each pass.
+		if ctx.calls != ctx.n+2 {
+			t.Errorf("%v: want %d checks, got %d", size, ctx.n+2, ctx.calls)
+		}
+		if m.Bounds() != image.Rect(0, 0, size.X, size.Y) {
+			t.Errorf("%v: want bounds %v, got %v", size, image.Rect(0, 0, size.X, size.Y), m.Bounds())
+		}
+	}
+}
+
+func Test_RunPassStep(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 300, 200))
+	p := newPlan(300, 200, 100, 100, 3, 2, Bilinear)
+
+	type call struct{ pass, done, total int }
+	var calls []call
+	var err error
+	p.step = func(pass, done, total int) error {
+		calls = append(calls, call{pass, done, total})
+		if pass == 0 && done == 2*passColumns {
+			err = errors.New("stop")
+		}
+		return err
+	}
+	p.apply(img)
+
+	// The first pass writes one column per source row, the second one per
+	// result column; both stop at the first error.
+	want := []call{{0, 0, 200}, {0, passColumns, 200}, {0, 2 * passColumns, 200}, {1, 0, 100}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("want steps %v, got %v", want, calls)
+	}
+}
@@ -1,8 +1,12 @@
 package resize
 
 import (
+	"bytes"
 	"image"
 	"image/color"
+	"image/draw"
+	"math"
+	"reflect"
 	"runtime"
 	"testing"
 )
@@ -299,6 +303,138 @@ func Benchmark_Lanczos3_RGBA(b *testing.B) {
 	benchRGBA(b, Lanczos3)
 }
 
+func Benchmark_Reduction(b *testing.B) {
+	m := image.NewRGBA(image.Rect(0, 0, 4*benchMaxX, 4*benchMaxY))
+	for i := range m.Pix {
+		m.Pix[i] = uint8(i * 7)
+	}
+
+	var out image.Image
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = Resize(benchMaxX/3, benchMaxY/3, m, Bicubic)
+	}
+	out.At(0, 0)
+}
+
+func benchArea(b *testing.B, interp InterpolationFunction) {
+	m := image.NewRGBA(image.Rect(0, 0, 4000, 4000))
+	for i := range m.Pix {
+		m.Pix[i] = uint8(i * 7)
+	}
+
+	var out image.Image
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = Resize(800, 0, m, interp)
+	}
+	out.At(0, 0)
+}
+
+func Benchmark_ReductionArea(b *testing.B)     { benchArea(b, Area) }
+func Benchmark_ReductionBilinear(b *testing.B) { benchArea(b, Bilinear) }
+
+func Test_AreaAverages(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 12, 12))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 37)
+	}
+
+	m := Resize(4, 4, img, Area).(*image.Gray)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			sum := 0
+			for j := 0; j < 3; j++ {
+				for i := 0; i < 3; i++ {
+					sum += int(img.GrayAt(3*x+i, 3*y+j).Y)
+				}
+			}
+			if got, want := int(m.GrayAt(x, y).Y), (sum+4)/9; got-want > 1 || want-got > 1 {
+				t.Errorf("(%d,%d): want block average %d, got %d", x, y, want, got)
+			}
+		}
+	}
+
+	// Enlarging by an integer factor repeats the pixels.
+	m = Resize(36, 36, img, Area).(*image.Gray)
+	for y := 0; y < 36; y++ {
+		for x := 0; x < 36; x++ {
+			if got, want := m.GrayAt(x, y).Y, img.GrayAt(x/3, y/3).Y; got != want {
+				t.Fatalf("(%d,%d): want %d, got %d", x, y, want, got)
+			}
+		}
+	}
+}
+
+func Test_Lanczos(t *testing.T) {
+	if Lanczos(0) != NearestNeighbor || Lanczos(2) != Lanczos2 || Lanczos(3) != Lanczos3 {
+		t.Error("Lanczos does not return the named interpolation functions")
+	}
+	for a := uint(1); a <= 5; a++ {
+		taps, kernel := Lanczos(a).kernel()
+		if taps != 2*int(a) {
+			t.Errorf("a=%d: want %d taps, got %d", a, 2*a, taps)
+		}
+		if kernel(0) != 1 || kernel(float64(a)) != 0 || math.Abs(kernel(1)) > 1e-12 {
+			t.Errorf("a=%d: kernel is not a Lanczos kernel", a)
+		}
+	}
+	// Large a are limited and never reach the encoding of CubicBC.
+	for _, a := range []uint{65, 1 << 16, 1<<17 + 5} {
+		if f := Lanczos(a); f != Lanczos(64) || !f.valid() {
+			t.Errorf("a=%d: want Lanczos(64), got %d", a, f)
+		}
+	}
+	if f := lanczosBase + 65; f.valid() {
+		t.Errorf("%d is valid", f)
+	}
+	_, k3 := Lanczos3.kernel()
+	k := lanczosKernel(3)
+	for x := -3.5; x < 3.5; x += 0.1 {
+		if math.Abs(k(x)-k3(x)) > 1e-12 {
+			t.Errorf("lanczosKernel(3)(%g) = %g, want %g", x, k(x), k3(x))
+		}
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 40, 30))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 3)
+	}
+	if m := Resize(17, 11, img, Lanczos(4)); m.Bounds() != image.Rect(0, 0, 17, 11) {
+		t.Errorf("want bounds %v, got %v", image.Rect(0, 0, 17, 11), m.Bounds())
+	}
+}
+
+func Test_CubicBC(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 23, 17))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 29)
+	}
+	for _, size := range []image.Point{{9, 7}, {50, 40}} {
+		got := Resize(uint(size.X), uint(size.Y), img, CubicBC(1.0/3, 1.0/3)).(*image.RGBA)
+		want := Resize(uint(size.X), uint(size.Y), img, MitchellNetravali).(*image.RGBA)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%v: CubicBC(1/3, 1/3) differs from MitchellNetravali", size)
+		}
+	}
+
+	if CubicBC(0, 0.5) != CubicBC(0, 0.5) {
+		t.Error("same parameters give different interpolation functions")
+	}
+	if CubicBC(-3, 0) != CubicBC(0, 0) || CubicBC(0.5, 7) != CubicBC(0.5, 1) {
+		t.Error("parameters outside of [0,1] are not clamped")
+	}
+	if !CubicBC(1, 1).valid() {
+		t.Error("CubicBC(1, 1) is not valid")
+	}
+	_, catmullRom := CubicBC(0, 0.5).kernel()
+	for x := -2.5; x < 2.5; x += 0.1 {
+		if math.Abs(catmullRom(x)-cubic(x)) > 1e-12 {
+			t.Errorf("CubicBC(0, 0.5) at %g: want %g, got %g", x, cubic(x), catmullRom(x))
+		}
+	}
+}
+
 func benchYCbCr(b *testing.B, interp InterpolationFunction) {
 	m := image.NewYCbCr(image.Rect(0, 0, benchMaxX, benchMaxY), image.YCbCrSubsampleRatio422)
 	// Initialize m's pixels to create a non-uniform image.
@@ -342,3 +478,275 @@ func Benchmark_Lanczos2_YCC(b *testing.B) {
 func Benchmark_Lanczos3_YCC(b *testing.B) {
 	benchYCbCr(b, Lanczos3)
 }
+
+func Test_ResizeYCbCrSeparate(t *testing.T) {
+	m := image.NewYCbCr(image.Rect(0, 0, 60, 40), image.YCbCrSubsampleRatio420)
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+			m.Y[m.YOffset(x, y)] = uint8(16*y + x)
+			m.Cb[m.COffset(x, y)] = uint8(y + 16*x)
+			m.Cr[m.COffset(x, y)] = uint8(3*y + x)
+		}
+	}
+
+	want := Resize(25, 17, m, Lanczos3).(*image.YCbCr)
+	got := ResizeYCbCrSeparate(25, 17, m, Lanczos3, NearestNeighbor)
+	if got.Bounds() != want.Bounds() || got.SubsampleRatio != want.SubsampleRatio {
+		t.Fatalf("want %v %v, got %v %v", want.Bounds(), want.SubsampleRatio, got.Bounds(), got.SubsampleRatio)
+	}
+	for i := range want.Y {
+		if got.Y[i] != want.Y[i] {
+			t.Fatalf("luma differs at %d: want %d, got %d", i, want.Y[i], got.Y[i])
+		}
+	}
+
+	same := ResizeYCbCrSeparate(25, 17, m, Lanczos3, Lanczos3)
+	for i := range want.Cb {
+		if same.Cb[i] != want.Cb[i] || same.Cr[i] != want.Cr[i] {
+			t.Fatalf("chroma differs at %d", i)
+		}
+	}
+}
+
+func benchYCbCrSeparate(b *testing.B, luma, chroma InterpolationFunction) {
+	m := image.NewYCbCr(image.Rect(0, 0, benchMaxX, benchMaxY), image.YCbCrSubsampleRatio422)
+	var out image.Image
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = ResizeYCbCrSeparate(benchWidth, benchHeight, m, luma, chroma)
+	}
+	out.At(0, 0)
+}
+
+func Benchmark_Lanczos3_YCC_Separate(b *testing.B) {
+	benchYCbCrSeparate(b, Lanczos3, Lanczos3)
+}
+
+func Benchmark_Lanczos3_Nearest_YCC_Separate(b *testing.B) {
+	benchYCbCrSeparate(b, Lanczos3, NearestNeighbor)
+}
+
+func Test_RepeatedResizeDoesNotDrift(t *testing.T) {
+	var m image.Image = image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for i := 0; i < 10; i++ {
+		m = Resize(400, 300, m, Bilinear)
+		if m.Bounds() != image.Rect(0, 0, 400, 300) {
+			t.Fatalf("iteration %d: got %v", i, m.Bounds())
+		}
+		m = Resize(800, 600, m, Bilinear)
+		if m.Bounds() != image.Rect(0, 0, 800, 600) {
+			t.Fatalf("iteration %d: got %v", i, m.Bounds())
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		m = Resize(400, 0, m, Bilinear)
+		if m.Bounds() != image.Rect(0, 0, 400, 300) {
+			t.Fatalf("iteration %d: got %v", i, m.Bounds())
+		}
+		m = Resize(0, 600, m, Bilinear)
+		if m.Bounds() != image.Rect(0, 0, 800, 600) {
+			t.Fatalf("iteration %d: got %v", i, m.Bounds())
+		}
+	}
+}
+
+func Test_IntegerWeightsMatchFloat(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 97, 83))
+	for y := 0; y < 83; y++ {
+		for x := 0; x < 97; x++ {
+			i := m.PixOffset(x, y)
+			// A smooth image, as overshoots are clamped between the passes.
+			a := 192 + 60*math.Sin(float64(x+y)/11)
+			m.Pix[i+0] = uint8(a/2 + a/2*math.Sin(float64(x)/7)*math.Cos(float64(y)/5))
+			m.Pix[i+1] = uint8(a / 2 * (1 + math.Cos(float64(x*y)/300)))
+			m.Pix[i+2] = uint8(a * float64(x+y) / 180)
+			m.Pix[i+3] = uint8(a)
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{Bilinear, Bicubic} {
+		got := Resize(40, 31, m, interp).(*image.RGBA)
+		want := resizeFloat(newFloatImageFromImage(m), 40, 31, 97./40, 83./31, interp)
+		for i, v := range got.Pix {
+			w := float64(want.Pix[i]) / 0x101
+			if d := float64(v) - w; d < -1 || d > 1 {
+				t.Fatalf("%v: sample %d: want %.2f, got %d", interp, i, w, v)
+			}
+		}
+	}
+}
+
+func Test_ResizeParallel(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 61, 47))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	gray16 := image.NewGray16(image.Rect(3, 5, 64, 52))
+	for i := range gray16.Pix {
+		gray16.Pix[i] = uint8(i * 13)
+	}
+
+	for _, img := range []image.Image{rgba, gray16} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+			for _, size := range []image.Point{{30, 1}, {100, 70}} {
+				want := Resize(uint(size.X), uint(size.Y), img, interp)
+				for _, workers := range []int{-1, 0, 1, 2, 3, 7, 200} {
+					m := ResizeParallel(workers, uint(size.X), uint(size.Y), img, interp)
+					if m.Bounds() != want.Bounds() {
+						t.Fatalf("%T %v %d workers: want bounds %v, got %v", img, interp, workers, want.Bounds(), m.Bounds())
+					}
+					for y := 0; y < size.Y; y++ {
+						for x := 0; x < size.X; x++ {
+							if m.At(x, y) != want.At(x, y) {
+								t.Fatalf("%T %v %d workers: pixel (%d,%d) differs", img, interp, workers, x, y)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if n := (&ResizePlan{width: 100, height: 3, workers: 8}).parallelism(); n != 3 {
+		t.Errorf("want 3 workers for 3 rows, got %d", n)
+	}
+}
+
+func Test_ResizeAlphaMask(t *testing.T) {
+	alpha := image.NewAlpha(image.Rect(2, 3, 66, 19))
+	alpha16 := image.NewAlpha16(image.Rect(0, 0, 64, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 64; x++ {
+			alpha.SetAlpha(x+2, y+3, color.Alpha{uint8(4 * x)})
+			alpha16.SetAlpha16(x, y, color.Alpha16{uint16(1024 * x)})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+		for _, width := range []uint{20, 150} {
+			for _, img := range []image.Image{alpha, alpha16} {
+				m := Resize(width, 5, img, interp)
+				var a func(x int) uint32
+				switch m := m.(type) {
+				case *image.Alpha:
+					a = func(x int) uint32 { return uint32(m.AlphaAt(x, 2).A) }
+				case *image.Alpha16:
+					a = func(x int) uint32 { return uint32(m.Alpha16At(x, 2).A) }
+				default:
+					t.Fatalf("%T: want a mask, got %T", img, m)
+				}
+				for x := 1; x < int(width); x++ {
+					if a(x) < a(x-1) {
+						t.Errorf("%T width %d interp %v: alpha falls from %d to %d at %d", img, width, interp, a(x-1), a(x), x)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_OutputSize(t *testing.T) {
+	sizes := []image.Point{{0, 0}, {1, 0}, {0, 1}, {7, 0}, {0, 7}, {33, 19}, {1, 1}, {640, 0}, {0, 3}}
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 100, 75), image.Rect(5, 9, 18, 1009), image.Rect(0, 0, 3, 1), image.Rect(4, 4, 4, 10)} {
+		img := image.NewGray(r)
+		for _, s := range sizes {
+			w, h := OutputSize(uint(s.X), uint(s.Y), img)
+			if want := Resize(uint(s.X), uint(s.Y), img, NearestNeighbor).Bounds().Size(); image.Pt(w, h) != want {
+				t.Errorf("%v to %v: want %v, got %v", r, s, want, image.Pt(w, h))
+			}
+		}
+	}
+}
+
+func Benchmark_Nearest_RGBA_1080p(b *testing.B) {
+	m := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
+	for i := range m.Pix {
+		m.Pix[i] = uint8(i * 7)
+	}
+	var out image.Image
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out = Resize(960, 540, m, NearestNeighbor)
+	}
+	out.At(0, 0)
+}
+
+func Test_ResizeFlatGray16(t *testing.T) {
+	const v = 0x7a3f
+	r := image.Rect(0, 0, 61, 37)
+	gray := image.NewGray16(r)
+	rgba := image.NewRGBA64(r)
+	nrgba := image.NewNRGBA64(r)
+	draw.Draw(gray, r, image.NewUniform(color.Gray16{v}), image.ZP, draw.Src)
+	draw.Draw(rgba, r, image.NewUniform(color.RGBA64{v, v, v, 0xffff}), image.ZP, draw.Src)
+	draw.Draw(nrgba, r, image.NewUniform(color.NRGBA64{v, v, v, 0xffff}), image.ZP, draw.Src)
+
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Bicubic, MitchellNetravali, Lanczos3} {
+		for _, size := range []image.Point{{13, 7}, {29, 50}, {150, 90}} {
+			for _, img := range []image.Image{gray, rgba, nrgba} {
+				m := Resize(uint(size.X), uint(size.Y), img, interp)
+				b := m.Bounds()
+				for y := b.Min.Y; y < b.Max.Y; y++ {
+					for x := b.Min.X; x < b.Max.X; x++ {
+						if r, g, b, _ := m.At(x, y).RGBA(); r != v || g != v || b != v {
+							t.Fatalf("%T %v interp %v: got (%#x, %#x, %#x) at (%d, %d), want %#x", img, size, interp, r, g, b, x, y, v)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// The average of the row is 0x1000.c, which rounds up.
+	row := image.NewGray16(image.Rect(0, 0, 4, 1))
+	for x, y := range []uint16{0x1000, 0x1001, 0x1001, 0x1001} {
+		row.SetGray16(x, 0, color.Gray16{y})
+	}
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+		if y := Resize(1, 1, row, interp).(*image.Gray16).Gray16At(0, 0).Y; y != 0x1001 {
+			t.Errorf("interp %v: got %#x, want 0x1001", interp, y)
+		}
+	}
+}
+
+func Test_QuantizeWeightsSaturates(t *testing.T) {
+	coeffs := make([]int16, 3)
+	quantizeWeights(coeffs, []float64{-1, 3, -1}, 1)
+	if coeffs[0] >= 0 || coeffs[1] != math.MaxInt16 || coeffs[2] >= 0 {
+		t.Errorf("weights (-1, 3, -1): got %v, want the middle weight saturated", coeffs)
+	}
+
+	quantizeWeights(coeffs, []float64{-1, 2, -1}, 0)
+	for i, c := range coeffs {
+		if c != math.MinInt16 && c != math.MaxInt16 && c != 0 {
+			t.Errorf("weights summing to zero: coefficient %d is %d", i, c)
+		}
+	}
+}
+
+func Test_ResizeResultTypes(t *testing.T) {
+	r := image.Rect(0, 0, 8, 8)
+	nrgba := image.NewNRGBA(r)
+	for _, tt := range []struct {
+		img    image.Image
+		interp InterpolationFunction
+		want   image.Image
+	}{
+		{image.NewRGBA(r), Bilinear, &image.RGBA{}},
+		{nrgba, Bilinear, &image.RGBA{}},
+		{image.NewCMYK(r), Bilinear, &image.RGBA{}},
+		{image.NewYCbCr(r, image.YCbCrSubsampleRatio420), Bilinear, &image.YCbCr{}},
+		{image.NewGray16(r), Bilinear, &image.Gray16{}},
+		{image.NewAlpha(r), Bilinear, &image.Alpha{}},
+		{struct{ *image.NRGBA }{nrgba}, Bilinear, &image.NRGBA64{}},
+		{image.NewNRGBA64(r), Bilinear, &image.RGBA64{}},
+		{nrgba, NearestNeighbor, &image.NRGBA{}},
+		{image.NewNRGBA64(r), NearestNeighbor, &image.NRGBA64{}},
+		{image.NewCMYK(r), NearestNeighbor, &image.RGBA64{}},
+	} {
+		if m := Resize(5, 3, tt.img, tt.interp); reflect.TypeOf(m) != reflect.TypeOf(tt.want) {
+			t.Errorf("%T with %v: want %T, got %T", tt.img, tt.interp, tt.want, m)
+		}
+	}
+}
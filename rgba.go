@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+)
+
+// ResizeRGBA scales an image like Resize, but always returns an *image.RGBA,
+// filtering other images with 8-bit temporary buffers.
+func ResizeRGBA(width, height uint, img image.Image, interp InterpolationFunction) *image.RGBA {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: convert input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		if rgba, ok := img.(*image.RGBA); ok {
+			return rgba
+		}
+		result := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(result, result.Bounds(), img, b.Min, draw.Src)
+		return result
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(width), int(height), scaleX, scaleY, interp)
+	switch img.(type) {
+	case *image.RGBA, *image.NRGBA, *image.CMYK, *image.Gray, *image.YCbCr:
+		m := p.apply(img)
+		if rgba, ok := m.(*image.RGBA); ok {
+			return rgba
+		}
+		result := image.NewRGBA(m.Bounds())
+		draw.Draw(result, result.Bounds(), m, image.ZP, draw.Src)
+		return result
+	}
+
+	cpus := p.parallelism()
+	wg := sync.WaitGroup{}
+
+	// 8-bit precision
+	temp := image.NewRGBA(image.Rect(0, 0, b.Dy(), int(width)))
+	result := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+
+	// horizontal filter, results in transposed temporary image
+	coeffs16, offset, filterLength := p.weights16X()
+	wg.Add(cpus)
+	for i := 0; i < cpus; i++ {
+		slice := makeSlice(temp, i, cpus).(*image.RGBA)
+		go func() {
+			defer wg.Done()
+			if rgba64, ok := img.(*image.RGBA64); ok {
+				resizeRGBA64ToRGBA(rgba64, slice, scaleX, coeffs16, offset, filterLength)
+			} else {
+				resizeGenericRGBA(img, slice, scaleX, coeffs16, offset, filterLength)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// horizontal filter on transposed image, result is not transposed
+	coeffs8, offset, filterLength := p.weights8Y()
+	wg.Add(cpus)
+	for i := 0; i < cpus; i++ {
+		slice := makeSlice(result, i, cpus).(*image.RGBA)
+		go func() {
+			defer wg.Done()
+			resizeRGBA(temp, slice, scaleY, coeffs8, offset, filterLength)
+		}()
+	}
+	wg.Wait()
+	return result
+}
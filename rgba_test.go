@@ -0,0 +1,79 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeRGBA(t *testing.T) {
+	rgba64 := image.NewRGBA64(image.Rect(3, 4, 63, 44))
+	for y := 4; y < 44; y++ {
+		for x := 3; x < 63; x++ {
+			a := uint16(0x8000 + x*500)
+			rgba64.SetRGBA64(x, y, color.RGBA64{a / 2, uint16(x*y*11) % a, a / 3, a})
+		}
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+		if i%4 == 3 {
+			rgba.Pix[i] = 0xff
+		}
+	}
+
+	for _, img := range []image.Image{rgba64, rgba} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+			for _, size := range []image.Point{{25, 15}, {90, 70}} {
+				m := ResizeRGBA(uint(size.X), uint(size.Y), img, interp)
+				want := Resize(uint(size.X), uint(size.Y), img, interp)
+				if m.Bounds() != want.Bounds() {
+					t.Fatalf("%T: want bounds %v, got %v", img, want.Bounds(), m.Bounds())
+				}
+				for y := 0; y < size.Y; y++ {
+					for x := 0; x < size.X; x++ {
+						c := m.RGBAAt(x, y)
+						w := color.RGBAModel.Convert(want.At(x, y)).(color.RGBA)
+						if absDiff(uint32(c.R), uint32(w.R)) > 1 || absDiff(uint32(c.G), uint32(w.G)) > 1 ||
+							absDiff(uint32(c.B), uint32(w.B)) > 1 || absDiff(uint32(c.A), uint32(w.A)) > 1 {
+							t.Fatalf("%T %v %v: pixel (%d,%d): want %v, got %v", img, interp, size, x, y, w, c)
+						}
+						if c.R > c.A || c.G > c.A || c.B > c.A {
+							t.Fatalf("%T %v %v: pixel (%d,%d): color exceeds alpha: %v", img, interp, size, x, y, c)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if m := ResizeRGBA(60, 40, rgba64, Bilinear); m.RGBAAt(5, 5) != color.RGBAModel.Convert(rgba64.At(8, 9)) {
+		t.Errorf("unscaled image not converted: want %v, got %v", rgba64.At(8, 9), m.RGBAAt(5, 5))
+	}
+}
+
+func benchRGBA64Source() *image.RGBA64 {
+	img := image.NewRGBA64(image.Rect(0, 0, 1024, 768))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	return img
+}
+
+func Benchmark_ResizeRGBA(b *testing.B) {
+	img := benchRGBA64Source()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ResizeRGBA(300, 225, img, Lanczos3)
+	}
+}
+
+func Benchmark_ResizeRGBA64Source(b *testing.B) {
+	img := benchRGBA64Source()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Resize(300, 225, img, Lanczos3)
+	}
+}
@@ -0,0 +1,189 @@
+// +build go1.7
+
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"context"
+	"errors"
+	"image"
+	"runtime"
+)
+
+// ErrMemoryLimit is returned by ResizeRobust if not even a single row of the
+// result can be computed within RobustOptions.MaxMemory.
+var ErrMemoryLimit = errors.New("resize: memory limit too small")
+
+// RobustOptions configures ResizeRobust.
+type RobustOptions struct {
+	// MaxMemory limits the size in bytes of the working buffers. The result
+	// is computed in horizontal bands of at most bandRows rows, lower if the
+	// limit requires it. The returned image is not included. 0 means no
+	// limit.
+	MaxMemory int
+	// Progress, if not nil, is called after every band with the number of
+	// finished rows of the result and its height.
+	Progress func(done, total int)
+	// MaxParallelism limits the number of goroutines used for a band.
+	// 0 means GOMAXPROCS.
+	MaxParallelism int
+}
+
+// band is a range of result rows together with the source rows it reads.
+type band struct {
+	y0, y1     int
+	src0, src1 int
+}
+
+// memory returns the size in bytes of the buffers needed to compute b for a
+// source and result of the given widths.
+func (b band) memory(srcWidth, width int) int {
+	return 16 * ((b.src1-b.src0)*(srcWidth+width) + (b.y1-b.y0)*width)
+}
+
+// ResizeRobust scales an image like Resize and is meant for servers that
+// process untrusted input. Memory use, progress reporting and parallelism
+// are controlled by opts. The image is processed with float precision and
+// the result is an *image.RGBA64.
+//
+// ctx is checked before every band, also without a memory limit. If it is
+// cancelled, the partially computed image is returned together with
+// ctx.Err(); rows that were not computed are transparent.
+func ResizeRobust(ctx context.Context, width, height uint, img image.Image, interp InterpolationFunction, opts RobustOptions) (image.Image, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img, nil
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img, nil
+	}
+
+	workers := opts.MaxParallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	w, h := int(width), int(height)
+
+	coeffsX, offsetX, lengthX := createWeightsInterp(w, interp, scaleX, 0, 0)
+	indexX := borderIndex(coeffsX, offsetX, lengthX, b.Dx(), Replicate)
+	coeffsY, offsetY, lengthY := createWeightsInterp(h, interp, scaleY, 0, 0)
+	indexY := borderIndex(coeffsY, offsetY, lengthY, b.Dy(), Replicate)
+
+	bands, err := splitBands(indexY, lengthY, b.Dx(), w, opts.MaxMemory)
+	if err != nil {
+		return nil, err
+	}
+
+	var maxSrc, maxRows int
+	for _, bd := range bands {
+		if n := bd.src1 - bd.src0; n > maxSrc {
+			maxSrc = n
+		}
+		if n := bd.y1 - bd.y0; n > maxRows {
+			maxRows = n
+		}
+	}
+	srcBuf := make([]float32, 4*maxSrc*b.Dx())
+	tempBuf := make([]float32, 4*maxSrc*w)
+	outBuf := make([]float32, 4*maxRows*w)
+	index := make([]int, maxRows*lengthY)
+	var src, temp, out floatImage
+
+	result := image.NewRGBA64(image.Rect(0, 0, w, h))
+	for _, bd := range bands {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		n := bd.src1 - bd.src0
+		src = floatImage{Pix: srcBuf[:4*n*b.Dx()], Stride: 4 * b.Dx(), Rect: image.Rect(0, 0, b.Dx(), n), Channels: 4}
+		readFloatRows(img, &src, bd.src0, workers)
+
+		// horizontal filter, results in transposed temporary image
+		temp = floatImage{Pix: tempBuf[:4*n*w], Stride: 4 * n, Rect: image.Rect(0, 0, n, w), Channels: 4}
+		parallelRowsN(w, workers, func(y0, y1 int) {
+			convolveFloat(&src, &temp, y0, y1, coeffsX, indexX, lengthX)
+		})
+
+		// horizontal filter on transposed image, result is not transposed
+		rows := bd.y1 - bd.y0
+		for i, xi := range indexY[bd.y0*lengthY : bd.y1*lengthY] {
+			index[i] = xi - bd.src0
+		}
+		coeffs := coeffsY[bd.y0*lengthY : bd.y1*lengthY]
+		out = floatImage{Pix: outBuf[:4*rows*w], Stride: 4 * w, Rect: image.Rect(0, 0, w, rows), Channels: 4}
+		parallelRowsN(rows, workers, func(y0, y1 int) {
+			convolveFloat(&temp, &out, y0, y1, coeffs, index, lengthY)
+		})
+
+		o := result.PixOffset(0, bd.y0)
+		for _, v := range out.Pix {
+			value := clampFloatUint16(v)
+			result.Pix[o+0] = uint8(value >> 8)
+			result.Pix[o+1] = uint8(value)
+			o += 2
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(bd.y1, h)
+		}
+	}
+	return result, nil
+}
+
+// splitBands splits the rows of a result, whose vertical filter taps read
+// the source rows in index, into bands of at most bandRows rows that fit
+// into maxMemory bytes.
+func splitBands(index []int, filterLength, srcWidth, width, maxMemory int) ([]band, error) {
+	height := len(index) / filterLength
+	var bands []band
+	for y := 0; y < height; {
+		bd := band{y0: y, y1: y, src0: int(^uint(0) >> 1)}
+		for bd.y1 < height && bd.y1-bd.y0 < bandRows {
+			next := bd
+			for _, xi := range index[bd.y1*filterLength : (bd.y1+1)*filterLength] {
+				if xi < next.src0 {
+					next.src0 = xi
+				}
+				if xi+1 > next.src1 {
+					next.src1 = xi + 1
+				}
+			}
+			next.y1++
+			if maxMemory > 0 && next.memory(srcWidth, width) > maxMemory {
+				break
+			}
+			bd = next
+		}
+		if bd.y1 == bd.y0 {
+			return nil, ErrMemoryLimit
+		}
+		bands = append(bands, bd)
+		y = bd.y1
+	}
+	return bands, nil
+}
@@ -0,0 +1,131 @@
+// +build go1.7
+
+package resize
+
+import (
+	"context"
+	"image"
+	"runtime"
+	"testing"
+)
+
+func gradientGray(w, h int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Pix[img.PixOffset(x, y)] = uint8(x*7 + y*3)
+		}
+	}
+	return img
+}
+
+func Test_ResizeRobustBands(t *testing.T) {
+	img := gradientGray(120, 90)
+	whole, err := ResizeRobust(context.Background(), 50, 40, img, Lanczos3, RobustOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	banded, err := ResizeRobust(context.Background(), 50, 40, img, Lanczos3, RobustOptions{
+		MaxMemory:      100000,
+		MaxParallelism: 1,
+		Progress:       func(done, total int) { calls++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls < 2 {
+		t.Fatalf("want several bands, got %d", calls)
+	}
+	if !banded.(*image.RGBA64).Bounds().Eq(whole.Bounds()) {
+		t.Fatalf("want bounds %v, got %v", whole.Bounds(), banded.Bounds())
+	}
+	for i, v := range banded.(*image.RGBA64).Pix {
+		if whole.(*image.RGBA64).Pix[i] != v {
+			t.Fatalf("banded result differs at %d", i)
+		}
+	}
+
+	// Without a limit, the bands are at most bandRows rows high.
+	calls = 0
+	if _, err := ResizeRobust(context.Background(), 50, 3*bandRows, img, Lanczos3, RobustOptions{
+		Progress: func(done, total int) { calls++ },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("want 3 bands without a limit, got %d", calls)
+	}
+
+	if _, err := ResizeRobust(context.Background(), 50, 40, img, Lanczos3, RobustOptions{MaxMemory: 100}); err != ErrMemoryLimit {
+		t.Errorf("want %v, got %v", ErrMemoryLimit, err)
+	}
+}
+
+func Test_ResizeRobustCancel(t *testing.T) {
+	img := gradientGray(100, 100)
+	opts := RobustOptions{MaxMemory: 30000}
+
+	var bands []int
+	opts.Progress = func(done, total int) { bands = append(bands, done) }
+	if _, err := ResizeRobust(context.Background(), 50, 50, img, Bilinear, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(bands) < 5 {
+		t.Fatalf("want at least 5 bands, got %d", len(bands))
+	}
+
+	// Cancel while the third band is computed.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	opts.Progress = func(done, total int) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+	}
+	m, err := ResizeRobust(ctx, 50, 50, img, Bilinear, opts)
+	if err != context.Canceled {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+	if calls != 2 {
+		t.Errorf("want 2 finished bands, got %d", calls)
+	}
+	partial := m.(*image.RGBA64)
+	for y := 0; y < 50; y++ {
+		a := partial.RGBA64At(10, y).A
+		if y < bands[1] && a != 0xffff {
+			t.Errorf("row %d of a finished band is missing", y)
+		}
+		if y >= bands[1] && a != 0 {
+			t.Errorf("row %d was computed after cancellation", y)
+		}
+	}
+}
+
+func Test_ResizeRobustMemory(t *testing.T) {
+	img := gradientGray(400, 400)
+	const limit = 256 << 10
+
+	measure := func(opts RobustOptions) uint64 {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		if _, err := ResizeRobust(context.Background(), 200, 200, img, Bilinear, opts); err != nil {
+			t.Fatal(err)
+		}
+		runtime.ReadMemStats(&after)
+		// The result is not bounded by the limit.
+		return after.TotalAlloc - before.TotalAlloc - 200*200*8
+	}
+
+	if used := measure(RobustOptions{}); used <= 4*limit {
+		t.Fatalf("unlimited resize only used %d bytes", used)
+	}
+	// Weight tables and the bookkeeping of bands are not part of the limit.
+	if used := measure(RobustOptions{MaxMemory: limit}); used > limit+128<<10 {
+		t.Errorf("want at most %d bytes, used %d", limit, used)
+	}
+}
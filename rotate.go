@@ -0,0 +1,241 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Rotate90 rotates an image by 90 degrees counter-clockwise, e.g. to apply
+// the EXIF orientation of a photo. The result has the width and height of
+// img swapped and its origin at (0,0). No pixel is interpolated: images of
+// the types of the image package are copied byte by byte into an image of
+// the same type, except that YCbCr images get full-resolution chroma;
+// other images are copied into an *image.RGBA64.
+func Rotate90(img image.Image) image.Image {
+	b := img.Bounds()
+	w := b.Dx()
+	return orient(img, b.Dy(), w, func(x, y int) (int, int) {
+		return w - 1 - y, x
+	})
+}
+
+// Rotate180 rotates an image by 180 degrees like Rotate90.
+func Rotate180(img image.Image) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	return orient(img, w, h, func(x, y int) (int, int) {
+		return w - 1 - x, h - 1 - y
+	})
+}
+
+// Rotate270 rotates an image by 270 degrees counter-clockwise, i.e. by 90
+// degrees clockwise, like Rotate90.
+func Rotate270(img image.Image) image.Image {
+	b := img.Bounds()
+	h := b.Dy()
+	return orient(img, h, b.Dx(), func(x, y int) (int, int) {
+		return y, h - 1 - x
+	})
+}
+
+// FlipH mirrors an image horizontally like Rotate90.
+func FlipH(img image.Image) image.Image {
+	b := img.Bounds()
+	w := b.Dx()
+	return orient(img, w, b.Dy(), func(x, y int) (int, int) {
+		return w - 1 - x, y
+	})
+}
+
+// FlipV mirrors an image vertically like Rotate90.
+func FlipV(img image.Image) image.Image {
+	b := img.Bounds()
+	h := b.Dy()
+	return orient(img, b.Dx(), h, func(x, y int) (int, int) {
+		return x, h - 1 - y
+	})
+}
+
+// RotateAngle rotates an image by degrees counter-clockwise about its
+// center, sampling the source with the kernel of interp at its original
+// size, e.g. to straighten a scan by a small angle with antialiased edges.
+// The result is an *image.RGBA64 just large enough to hold the rotated
+// image, with its origin at (0,0); pixels that are not covered by it, and
+// kernel taps beyond the border of img, take the background color, which
+// may be nil for transparent black. Multiples of 90 degrees give the same
+// pixels as Rotate90, Rotate180 and Rotate270 for interpolating kernels.
+// If degrees is NaN or infinite, img is returned unchanged.
+func RotateAngle(img image.Image, degrees float64, interp InterpolationFunction, background color.Color) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	// There is no angle to rotate by
+	if math.IsNaN(degrees) || math.IsInf(degrees, 0) {
+		return img
+	}
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	// Multiples of 90 degrees map pixel centers exactly onto pixel centers.
+	if math.Abs(sin) < 1e-12 {
+		sin = 0
+	}
+	if math.Abs(cos) < 1e-12 {
+		cos = 0
+	}
+	w, h := float64(b.Dx()), float64(b.Dy())
+	width := int(math.Ceil(math.Abs(w*cos) + math.Abs(h*sin) - 1e-9))
+	height := int(math.Ceil(math.Abs(w*sin) + math.Abs(h*cos) - 1e-9))
+
+	var bg [4]float32
+	if background != nil {
+		r, g, b, a := background.RGBA()
+		bg = [4]float32{float32(r), float32(g), float32(b), float32(a)}
+	}
+
+	in := newFloatImageFromImage(img)
+	out := newFloatImage(image.Rect(0, 0, width, height), 4)
+	taps, kernel := interp.kernel()
+	parallelRows(height, func(y0, y1 int) {
+		wx := make([]float64, taps)
+		wy := make([]float64, taps)
+		for y := y0; y < y1; y++ {
+			for x := 0; x < width; x++ {
+				// Map the center of the result pixel back into the source.
+				dx := float64(x) + 0.5 - float64(width)/2
+				dy := float64(y) + 0.5 - float64(height)/2
+				sx := w/2 + dx*cos - dy*sin - 0.5
+				sy := h/2 + dx*sin + dy*cos - 0.5
+
+				startX := int(math.Floor(sx)) - taps/2 + 1
+				startY := int(math.Floor(sy)) - taps/2 + 1
+				for i := 0; i < taps; i++ {
+					wx[i] = kernel(sx - float64(startX+i))
+					wy[i] = kernel(sy - float64(startY+i))
+				}
+
+				var rgba [4]float64
+				var sum float64
+				for j, ky := range wy {
+					if ky == 0 {
+						continue
+					}
+					yi := startY + j
+					for i, kx := range wx {
+						k := kx * ky
+						if k == 0 {
+							continue
+						}
+						xi := startX + i
+						p := bg[:]
+						if xi >= 0 && xi < in.Rect.Dx() && yi >= 0 && yi < in.Rect.Dy() {
+							o := in.PixOffset(xi, yi)
+							p = in.Pix[o : o+4]
+						}
+						for c := range rgba {
+							rgba[c] += k * float64(p[c])
+						}
+						sum += k
+					}
+				}
+
+				o := out.PixOffset(x, y)
+				for c, v := range rgba {
+					out.Pix[o+c] = float32(v / sum)
+				}
+			}
+		}
+	})
+	return out.RGBA64()
+}
+
+// orient returns a w x h image whose pixel (x, y) is the pixel src(x, y) of
+// img, relative to the origin of img.
+func orient(img image.Image, w, h int, src func(x, y int) (int, int)) image.Image {
+	b := img.Bounds()
+	r := image.Rect(0, 0, w, h)
+
+	var out image.Image
+	var in, pix []uint8
+	var inStride, stride, bpp int
+	switch input := img.(type) {
+	case *image.RGBA:
+		m := image.NewRGBA(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 4
+	case *image.NRGBA:
+		m := image.NewNRGBA(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 4
+	case *image.RGBA64:
+		m := image.NewRGBA64(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 8
+	case *image.NRGBA64:
+		m := image.NewNRGBA64(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 8
+	case *image.Gray:
+		m := image.NewGray(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 1
+	case *image.Gray16:
+		m := image.NewGray16(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 2
+	case *image.Alpha:
+		m := image.NewAlpha(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 1
+	case *image.Alpha16:
+		m := image.NewAlpha16(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 2
+	case *image.CMYK:
+		m := image.NewCMYK(r)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 4
+	case *image.Paletted:
+		m := image.NewPaletted(r, input.Palette)
+		out, in, inStride, pix, stride, bpp = m, input.Pix, input.Stride, m.Pix, m.Stride, 1
+	case *image.YCbCr:
+		m := image.NewYCbCr(r, image.YCbCrSubsampleRatio444)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				sx, sy := src(x, y)
+				sx, sy = sx+b.Min.X, sy+b.Min.Y
+				yi, ci := input.YOffset(sx, sy), input.COffset(sx, sy)
+				o := m.YOffset(x, y)
+				m.Y[o], m.Cb[o], m.Cr[o] = input.Y[yi], input.Cb[ci], input.Cr[ci]
+			}
+		}
+		return m
+	default:
+		m := image.NewRGBA64(r)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				sx, sy := src(x, y)
+				m.Set(x, y, img.At(sx+b.Min.X, sy+b.Min.Y))
+			}
+		}
+		return m
+	}
+
+	for y := 0; y < h; y++ {
+		row := pix[y*stride:]
+		for x := 0; x < w; x++ {
+			sx, sy := src(x, y)
+			copy(row[x*bpp:(x+1)*bpp], in[sy*inStride+sx*bpp:])
+		}
+	}
+	return out
+}
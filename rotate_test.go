@@ -0,0 +1,146 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_RotateFlip(t *testing.T) {
+	// 1 2 3
+	// 4 5 6
+	img := image.NewGray(image.Rect(5, 7, 8, 9))
+	for i := 0; i < 6; i++ {
+		img.SetGray(5+i%3, 7+i/3, color.Gray{uint8(i + 1)})
+	}
+
+	tests := []struct {
+		name string
+		fn   func(image.Image) image.Image
+		want [][]uint8
+	}{
+		{"Rotate90", Rotate90, [][]uint8{{3, 6}, {2, 5}, {1, 4}}},
+		{"Rotate180", Rotate180, [][]uint8{{6, 5, 4}, {3, 2, 1}}},
+		{"Rotate270", Rotate270, [][]uint8{{4, 1}, {5, 2}, {6, 3}}},
+		{"FlipH", FlipH, [][]uint8{{3, 2, 1}, {6, 5, 4}}},
+		{"FlipV", FlipV, [][]uint8{{4, 5, 6}, {1, 2, 3}}},
+	}
+	for _, test := range tests {
+		m, ok := test.fn(img).(*image.Gray)
+		if !ok {
+			t.Fatalf("%s: want *image.Gray, got %T", test.name, test.fn(img))
+		}
+		if r := image.Rect(0, 0, len(test.want[0]), len(test.want)); m.Bounds() != r {
+			t.Fatalf("%s: want bounds %v, got %v", test.name, r, m.Bounds())
+		}
+		for y, row := range test.want {
+			for x, v := range row {
+				if g := m.GrayAt(x, y).Y; g != v {
+					t.Errorf("%s: pixel (%d,%d): want %d, got %d", test.name, x, y, v, g)
+				}
+			}
+		}
+	}
+}
+
+func Test_RotateTypes(t *testing.T) {
+	r := image.Rect(1, 1, 8, 6)
+	rgba64 := image.NewRGBA64(r)
+	for i := range rgba64.Pix {
+		rgba64.Pix[i] = uint8(i * 7)
+	}
+	ycc := image.NewYCbCr(image.Rect(0, 0, 9, 7), image.YCbCrSubsampleRatio420)
+	for i := range ycc.Y {
+		ycc.Y[i] = uint8(i * 5)
+	}
+	for i := range ycc.Cb {
+		ycc.Cb[i], ycc.Cr[i] = uint8(i*11), uint8(i*13)
+	}
+	nycca := image.NewNYCbCrA(r, image.YCbCrSubsampleRatio422)
+	for i := range nycca.A {
+		nycca.A[i] = uint8(i * 3)
+	}
+	pal := image.NewPaletted(r, color.Palette{color.Black, color.White, color.Transparent})
+	for i := range pal.Pix {
+		pal.Pix[i] = uint8(i % 3)
+	}
+
+	for _, img := range []image.Image{rgba64, ycc.SubImage(r), nycca, pal} {
+		m := Rotate270(img)
+		b := img.Bounds()
+		if m.Bounds() != image.Rect(0, 0, b.Dy(), b.Dx()) {
+			t.Fatalf("%T: got bounds %v", img, m.Bounds())
+		}
+		for y := 0; y < b.Dx(); y++ {
+			for x := 0; x < b.Dy(); x++ {
+				want := color.RGBA64Model.Convert(img.At(b.Min.X+y, b.Max.Y-1-x))
+				if g := color.RGBA64Model.Convert(m.At(x, y)); g != want {
+					t.Fatalf("%T: pixel (%d,%d): want %v, got %v", img, x, y, want, g)
+				}
+			}
+		}
+	}
+}
+
+func Test_RotateAngle(t *testing.T) {
+	img := image.NewRGBA(image.Rect(2, 3, 42, 28))
+	for y := 3; y < 28; y++ {
+		for x := 2; x < 42; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(6 * x), uint8(9 * y), uint8(3 * (x + y)), 0xff})
+		}
+	}
+
+	for _, test := range []struct {
+		degrees float64
+		fn      func(image.Image) image.Image
+	}{{90, Rotate90}, {180, Rotate180}, {-90, Rotate270}, {270, Rotate270}} {
+		for _, interp := range []InterpolationFunction{Bilinear, Lanczos3} {
+			m := RotateAngle(img, test.degrees, interp, color.White)
+			want := test.fn(img)
+			if m.Bounds() != want.Bounds() {
+				t.Fatalf("%v degrees: want bounds %v, got %v", test.degrees, want.Bounds(), m.Bounds())
+			}
+			b := want.Bounds()
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					if g, w := m.At(x, y), color.RGBA64Model.Convert(want.At(x, y)); g != w {
+						t.Fatalf("%v degrees interp %v: pixel (%d,%d): want %v, got %v", test.degrees, interp, x, y, w, g)
+					}
+				}
+			}
+		}
+	}
+
+	// Rotating back gives the source in the middle of the larger result.
+	smooth := image.NewGray16(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			smooth.SetGray16(x, y, color.Gray16{uint16(700*x + 500*y)})
+		}
+	}
+	m := RotateAngle(RotateAngle(smooth, 30, Bicubic, nil), -30, Bicubic, nil)
+	if m.Bounds() != image.Rect(0, 0, 76, 76) {
+		t.Fatalf("want bounds %v, got %v", image.Rect(0, 0, 76, 76), m.Bounds())
+	}
+	for y := 10; y < 30; y++ {
+		for x := 10; x < 30; x++ {
+			r, _, _, _ := m.At(x+18, y+18).RGBA()
+			if want := uint32(700*x + 500*y); absDiff(r, want) > 0x100 {
+				t.Errorf("pixel (%d,%d): want %#x, got %#x", x, y, want, r)
+			}
+		}
+	}
+
+	// The corners of the result are background.
+	m = RotateAngle(img, 45, Bilinear, color.White)
+	if c := color.RGBA64Model.Convert(m.At(0, 0)); c != color.RGBA64Model.Convert(color.White) {
+		t.Errorf("corner: want white, got %v", c)
+	}
+
+	for _, degrees := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if m := RotateAngle(img, degrees, Bilinear, color.White); m != image.Image(img) {
+			t.Errorf("%v degrees: want the input image, got %T %v", degrees, m, m.Bounds())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import "image"
+
+// ResizeStaged scales an image like Resize to an *image.RGBA64 and also
+// returns the transposed result of the horizontal pass.
+func ResizeStaged(width, height uint, img image.Image, interp InterpolationFunction) (image.Image, *image.RGBA64) {
+	width, height, scaleX, scaleY := scaledSize(width, height, img.Bounds().Dx(), img.Bounds().Dy())
+
+	// Input image has no pixels
+	if img.Bounds().Dx() <= 0 || img.Bounds().Dy() <= 0 {
+		return img, nil
+	}
+
+	p := newPlan(img.Bounds().Dx(), img.Bounds().Dy(), int(width), int(height), scaleX, scaleY, interp)
+	temp := image.NewRGBA64(image.Rect(0, 0, img.Bounds().Dy(), int(width)))
+	result := image.NewRGBA64(image.Rect(0, 0, int(width), int(height)))
+
+	if interp == NearestNeighbor {
+		// horizontal filter, results in transposed temporary image
+		coeffs, offset, filterLength := p.weightsNearestX()
+		p.runPass(0, temp, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			nearestGeneric(img, slice, scaleX, coeffs, offset, filterLength)
+		})
+
+		// horizontal filter on transposed image, result is not transposed
+		coeffs, offset, filterLength = p.weightsNearestY()
+		p.runPass(1, result, func(s image.Image) {
+			slice := s.(*image.RGBA64)
+			nearestRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+		})
+		return result, temp
+	}
+
+	// horizontal filter, results in transposed temporary image
+	coeffs, offset, filterLength := p.weights16X()
+	p.runPass(0, temp, func(s image.Image) {
+		slice := s.(*image.RGBA64)
+		resizeGeneric(img, slice, scaleX, coeffs, offset, filterLength)
+	})
+
+	// horizontal filter on transposed image, result is not transposed
+	coeffs, offset, filterLength = p.weights16Y()
+	p.runPass(1, result, func(s image.Image) {
+		slice := s.(*image.RGBA64)
+		resizeRGBA64(temp, slice, scaleY, coeffs, offset, filterLength)
+	})
+	return result, temp
+}
+
+// ResizeStagedUpright is like ResizeStaged, but returns the intermediate image
+// in the orientation of the result: it is width pixels wide and oldHeight
+// pixels high, and its pixel (x, y) holds source row y filtered for output
+// column x. The intermediate is transposed back after the resize, which
+// costs an extra copy.
+func ResizeStagedUpright(width, height uint, img image.Image, interp InterpolationFunction) (image.Image, *image.RGBA64) {
+	result, temp := ResizeStaged(width, height, img, interp)
+	if temp == nil {
+		return result, nil
+	}
+	return result, transposeRGBA64(temp)
+}
+
+// transposeRGBA64 returns a copy of img with rows and columns swapped.
+func transposeRGBA64(img *image.RGBA64) *image.RGBA64 {
+	b := img.Bounds()
+	out := image.NewRGBA64(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		i := img.PixOffset(b.Min.X, b.Min.Y+y)
+		for x := 0; x < b.Dx(); x++ {
+			copy(out.Pix[out.PixOffset(y, x):], img.Pix[i:i+8])
+			i += 8
+		}
+	}
+	return out
+}
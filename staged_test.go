@@ -0,0 +1,73 @@
+package resize
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeStaged(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 30, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			v := uint16(x*2000 + y*300)
+			img.SetRGBA64(x, y, color.RGBA64{v, v / 2, v / 3, 0xffff})
+		}
+	}
+
+	for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+		result, intermediate := ResizeStaged(12, 9, img, interp)
+		if intermediate.Bounds() != image.Rect(0, 0, 20, 12) {
+			t.Fatalf("%v: intermediate bounds %v", interp, intermediate.Bounds())
+		}
+		if !bytes.Equal(result.(*image.RGBA64).Pix, Resize(12, 9, img, interp).(*image.RGBA64).Pix) {
+			t.Errorf("%v: result differs from Resize", interp)
+		}
+
+		// Run the vertical pass again on the intermediate.
+		_, scaleY := calcFactors(12, 9, 30, 20)
+		out := image.NewRGBA64(image.Rect(0, 0, 12, 9))
+		if interp == NearestNeighbor {
+			coeffs, offset, filterLength := createWeightsNearest(9, 2, blur, scaleY)
+			nearestRGBA64(intermediate, out, scaleY, coeffs, offset, filterLength)
+		} else {
+			taps, kernel := interp.kernel()
+			coeffs, offset, filterLength := createWeights16(9, taps, blur, scaleY, kernel)
+			resizeRGBA64(intermediate, out, scaleY, coeffs, offset, filterLength)
+		}
+		if !bytes.Equal(out.Pix, result.(*image.RGBA64).Pix) {
+			t.Errorf("%v: vertical pass on intermediate differs from result", interp)
+		}
+	}
+}
+
+func Test_ResizeStagedUpright(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 30, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			v := uint16(x*2000 + y*300)
+			img.SetRGBA64(x, y, color.RGBA64{v, v / 2, v / 3, 0xffff})
+		}
+	}
+
+	result, upright := ResizeStagedUpright(12, 9, img, Bilinear)
+	want, transposed := ResizeStaged(12, 9, img, Bilinear)
+	if upright.Bounds() != image.Rect(0, 0, 12, 20) {
+		t.Fatalf("want intermediate bounds %v, got %v", image.Rect(0, 0, 12, 20), upright.Bounds())
+	}
+	if !bytes.Equal(result.(*image.RGBA64).Pix, want.(*image.RGBA64).Pix) {
+		t.Error("result differs from ResizeStaged")
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 12; x++ {
+			if c, want := upright.RGBA64At(x, y), transposed.RGBA64At(y, x); c != want {
+				t.Fatalf("(%d,%d): want %v, got %v", x, y, want, c)
+			}
+		}
+	}
+	// Source columns increase to the right, so does the intermediate.
+	if upright.RGBA64At(0, 5).R >= upright.RGBA64At(11, 5).R {
+		t.Error("intermediate is not in the orientation of the result")
+	}
+}
@@ -18,6 +18,9 @@ package resize
 
 import (
 	"image"
+	"image/color"
+	"image/draw"
+	"math"
 )
 
 // Thumbnail will downscale provided image to max width and height preserving
@@ -53,3 +56,84 @@ func Thumbnail(maxWidth, maxHeight uint, img image.Image, interp InterpolationFu
 	}
 	return Resize(newWidth, newHeight, img, interp)
 }
+
+// Fit scales an image to the largest size that fits inside width x height
+// pixels, keeping the aspect ratio. Unlike Thumbnail, it enlarges small
+// images.
+func Fit(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	if width == 0 || height == 0 || b.Dx() <= 0 || b.Dy() <= 0 {
+		return Resize(width, height, img, interp)
+	}
+
+	// The side with the larger scale factor is scaled to fit, the other
+	// one follows and is at least one pixel.
+	if float64(b.Dx())*float64(height) > float64(b.Dy())*float64(width) {
+		if uint(0.7+float64(b.Dy())*float64(width)/float64(b.Dx())) == 0 {
+			return Resize(width, 1, img, interp)
+		}
+		return Resize(width, 0, img, interp)
+	}
+	if uint(0.7+float64(b.Dx())*float64(height)/float64(b.Dy())) == 0 {
+		return Resize(1, height, img, interp)
+	}
+	return Resize(0, height, img, interp)
+}
+
+// ResizePercent scales both dimensions of img by percent/100. If percent is
+// not a positive finite number, img is returned unchanged.
+func ResizePercent(percent float64, img image.Image, interp InterpolationFunction) image.Image {
+	if !(percent > 0) || math.IsInf(percent, 1) {
+		return img
+	}
+	b := img.Bounds()
+	return Resize(percentOf(b.Dx(), percent), percentOf(b.Dy(), percent), img, interp)
+}
+
+// percentOf returns percent/100 of size, rounded and at least 1.
+func percentOf(size int, percent float64) uint {
+	v := uint(float64(size)*percent/100 + 0.5)
+	if v < 1 {
+		v = 1
+	}
+	return v
+}
+
+// ResizeTo scales img to the size of dst and draws the result into dst.
+func ResizeTo(dst draw.Image, img image.Image, interp InterpolationFunction) {
+	r := dst.Bounds()
+	if r.Empty() {
+		return
+	}
+	m := Resize(uint(r.Dx()), uint(r.Dy()), img, interp)
+	draw.Draw(dst, r, m, m.Bounds().Min, draw.Src)
+}
+
+// previewBits is the number of bits kept per sample of a preview.
+const previewBits = 4
+
+// ResizeWithPreview scales an image like Resize and also returns a tiny
+// *image.NRGBA preview that fits into previewMax x previewMax pixels.
+func ResizeWithPreview(width, height uint, img image.Image, interp InterpolationFunction, previewMax uint) (full, preview image.Image) {
+	full = Resize(width, height, img, interp)
+	if previewMax == 0 {
+		previewMax = 1
+	}
+	small := Thumbnail(previewMax, previewMax, full, Bilinear)
+
+	b := small.Bounds()
+	p := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.NRGBAModel.Convert(small.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			p.SetNRGBA(x, y, color.NRGBA{quantize(c.R), quantize(c.G), quantize(c.B), quantize(c.A)})
+		}
+	}
+	return full, p
+}
+
+// quantize rounds v to previewBits bits and scales it back to 8 bits.
+func quantize(v uint8) uint8 {
+	const levels = 1<<previewBits - 1
+	return uint8((int(v)*levels + 0x7f) / 0xff * 0xff / levels)
+}
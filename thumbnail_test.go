@@ -2,6 +2,8 @@ package resize
 
 import (
 	"image"
+	"image/color"
+	"math"
 	"runtime"
 	"testing"
 )
@@ -45,3 +47,102 @@ func TestThumbnail(t *testing.T) {
 		}
 	}
 }
+
+var fitTests = []struct {
+	origWidth, origHeight         int
+	width, height                 uint
+	expectedWidth, expectedHeight int
+}{
+	{400, 200, 100, 100, 100, 50},
+	{200, 400, 100, 100, 50, 100},
+	{20, 10, 100, 100, 100, 50},
+	{10, 20, 100, 100, 50, 100},
+	{300, 200, 150, 100, 150, 100},
+	{1000, 2, 10, 10, 10, 1},
+	{2, 1000, 10, 10, 1, 10},
+}
+
+func TestFit(t *testing.T) {
+	for _, tt := range fitTests {
+		img := image.NewGray(image.Rect(0, 0, tt.origWidth, tt.origHeight))
+		b := Fit(tt.width, tt.height, img, Bilinear).Bounds()
+		if b.Dx() != tt.expectedWidth || b.Dy() != tt.expectedHeight {
+			t.Errorf("%dx%d into %dx%d: want %dx%d, got %dx%d", tt.origWidth, tt.origHeight, tt.width, tt.height,
+				tt.expectedWidth, tt.expectedHeight, b.Dx(), b.Dy())
+		}
+	}
+}
+
+var percentTests = []struct {
+	percent                       float64
+	expectedWidth, expectedHeight int
+}{
+	{50, 50, 40},
+	{25, 25, 20},
+	{33.3, 33, 27},
+	{150, 150, 120},
+	{0.1, 1, 1},
+}
+
+func TestResizePercent(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 100, 80))
+	for _, tt := range percentTests {
+		b := ResizePercent(tt.percent, img, Bilinear).Bounds()
+		if b.Dx() != tt.expectedWidth || b.Dy() != tt.expectedHeight {
+			t.Errorf("%g%%: want %dx%d, got %dx%d", tt.percent, tt.expectedWidth, tt.expectedHeight, b.Dx(), b.Dy())
+		}
+	}
+
+	for _, percent := range []float64{0, -50, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if m := ResizePercent(percent, img, Bilinear); m != image.Image(img) {
+			t.Errorf("%g%%: want the input image, got bounds %v", percent, m.Bounds())
+		}
+	}
+}
+
+func Test_ResizeWithPreview(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i / 13)
+	}
+
+	full, preview := ResizeWithPreview(150, 0, img, Lanczos3, 16)
+	want := Resize(150, 0, img, Lanczos3).(*image.RGBA)
+	if !full.Bounds().Eq(want.Bounds()) || string(full.(*image.RGBA).Pix) != string(want.Pix) {
+		t.Error("full result differs from Resize")
+	}
+	if b := preview.Bounds(); b.Dx() > 16 || b.Dy() > 16 || b.Dx() < 15 {
+		t.Errorf("preview bounds %v do not fit 16x16", b)
+	}
+	levels := make(map[uint8]bool)
+	for _, v := range preview.(*image.NRGBA).Pix {
+		levels[v] = true
+	}
+	if len(levels) > 16 {
+		t.Errorf("want at most 16 levels, got %d", len(levels))
+	}
+}
+
+func Test_ResizeTo(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 60; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(4 * x), 0x80, 0x40, 0xff})
+		}
+	}
+
+	dst := image.NewGray(image.Rect(10, 10, 40, 30))
+	ResizeTo(dst, img, Bilinear)
+	want := Resize(30, 20, img, Bilinear)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 30; x++ {
+			g := dst.GrayAt(10+x, 10+y).Y
+			if w := color.GrayModel.Convert(want.At(x, y)).(color.Gray).Y; g != w {
+				t.Fatalf("(%d,%d): want luminance %d, got %d", x, y, w, g)
+			}
+		}
+	}
+	if dst.GrayAt(10, 20).Y >= dst.GrayAt(39, 20).Y {
+		t.Error("luminance does not increase with red")
+	}
+}
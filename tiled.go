@@ -0,0 +1,263 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// ResizeTiledSchedule scales an image like Resize in independent tiles of at
+// most tileW x tileH pixels, calling run once with the function of each tile.
+func ResizeTiledSchedule(width, height uint, tileW, tileH int, img image.Image, interp InterpolationFunction, run func(tile func())) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	if tileW <= 0 {
+		tileW = int(width)
+	}
+	if tileH <= 0 {
+		tileH = int(height)
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(width), int(height), scaleX, scaleY, interp)
+	result := newTiledResult(img, image.Rect(0, 0, int(width), int(height)), interp)
+	p.tiles(img, tileW, tileH, func(tx, ty int, r image.Rectangle, tile func() image.Image) {
+		run(func() {
+			copyTile(result, r, tile())
+		})
+	})
+	return result
+}
+
+// ResizeToTileWriter scales an image like Resize and passes the result to
+// writeTile in tiles as they are computed. The first error of writeTile is
+// returned.
+func ResizeToTileWriter(width, height uint, tileW, tileH int, img image.Image, interp InterpolationFunction, writeTile func(tx, ty int, tile image.Image) error) error {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return nil
+	}
+
+	if tileW <= 0 {
+		tileW = int(width)
+	}
+	if tileH <= 0 {
+		tileH = int(height)
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(width), int(height), scaleX, scaleY, interp)
+	trivial := int(width) == b.Dx() && int(height) == b.Dy()
+	var err error
+	p.tiles(img, tileW, tileH, func(tx, ty int, r image.Rectangle, tile func() image.Image) {
+		if err != nil {
+			return
+		}
+		var m image.Image
+		if trivial {
+			// The tiles are copies of the source.
+			m = newTiledResult(img, r.Sub(r.Min), interp)
+			draw.Draw(m.(draw.Image), m.Bounds(), img, r.Min.Add(b.Min), draw.Src)
+		} else {
+			m = tile()
+		}
+		err = writeTile(tx, ty, m)
+	})
+	return err
+}
+
+// ResizeTiled scales an image like Resize, but holds the temporary image of
+// one band of rows at a time.
+func ResizeTiled(width, height uint, img image.Image, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Trivial case: return input image
+	if int(width) == b.Dx() && int(height) == b.Dy() {
+		return img
+	}
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	p := newPlan(b.Dx(), b.Dy(), int(width), int(height), scaleX, scaleY, interp)
+	result, _ := p.bands(img, func(done int) error {
+		return nil
+	})
+	return result
+}
+
+// tiles calls fn for every tile of at most tileW x tileH pixels of the
+// result of p, row by row, with the column and row of the tile, its region
+// r of the result and a function that computes it. The function returns the
+// pixels of r with the origin at (0,0); it only reads the source rows that
+// its kernel covers and shares no mutable state with other tiles.
+// Subsampled YCbCr images are converted to 4:4:4 chroma first, so that they
+// can be split at every row.
+func (p *ResizePlan) tiles(img image.Image, tileW, tileH int, fn func(tx, ty int, r image.Rectangle, tile func() image.Image)) {
+	if ycbcr, ok := img.(*image.YCbCr); ok && ycbcr.SubsampleRatio != image.YCbCrSubsampleRatio444 {
+		full := imageYCbCrToYCC(ycbcr)
+		full.SubsampleRatio = image.YCbCrSubsampleRatio444
+		img = full.YCbCr()
+	}
+	b := img.Bounds()
+	sub, ok := img.(imageWithSubImage)
+	if !ok {
+		sub = &croppedImage{img, b}
+	}
+
+	bounds := image.Rect(0, 0, p.width, p.height)
+	for y, ty := 0, 0; y < p.height; y, ty = y+tileH, ty+1 {
+		for x, tx := 0, 0; x < p.width; x, tx = x+tileW, tx+1 {
+			r := image.Rect(x, y, x+tileW, y+tileH).Intersect(bounds)
+			src0, src1 := p.srcRows(r.Min.Y, r.Max.Y)
+			fn(tx, ty, r, func() image.Image {
+				in := sub.SubImage(image.Rect(b.Min.X, b.Min.Y+src0, b.Max.X, b.Min.Y+src1))
+				return p.windowPlan(r, src0, src1-src0).apply(in)
+			})
+		}
+	}
+}
+
+// croppedImage is a view of the pixels of an image inside r, for images
+// without a SubImage method.
+type croppedImage struct {
+	image.Image
+	r image.Rectangle
+}
+
+func (p *croppedImage) Bounds() image.Rectangle {
+	return p.r
+}
+
+func (p *croppedImage) SubImage(r image.Rectangle) image.Image {
+	return &croppedImage{p.Image, r.Intersect(p.r)}
+}
+
+// bandRows is the number of result rows of the bands of ResizePlan.bands.
+const bandRows = 64
+
+// bands computes the result of p in bands of bandRows rows, each from the
+// source rows its kernel covers, into an image like that of
+// ResizeTiledSchedule. Before every band, next is called with the number of
+// finished rows; if it returns an error, no further band is computed and
+// the partial result is returned together with the error.
+func (p *ResizePlan) bands(img image.Image, next func(done int) error) (image.Image, error) {
+	result := newTiledResult(img, image.Rect(0, 0, p.width, p.height), p.interp)
+	var err error
+	p.tiles(img, p.width, bandRows, func(tx, ty int, r image.Rectangle, tile func() image.Image) {
+		if err != nil {
+			return
+		}
+		if err = next(r.Min.Y); err == nil {
+			copyTile(result, r, tile())
+		}
+	})
+	return result, err
+}
+
+// srcRows returns the source rows [src0,src1) that the vertical pass of p
+// reads for the result rows [y0,y1). The taps are the same as those of
+// createWeights8, createWeights16 and createWeightsNearest.
+func (p *ResizePlan) srcRows(y0, y1 int) (src0, src1 int) {
+	taps, _ := p.interp.kernel()
+	filterLength := taps * int(math.Max(math.Ceil(blur*p.scaleY), 1))
+	start := func(y int) int {
+		return int(p.scaleY*(float64(y)+0.5)-0.5) - filterLength/2 + 1
+	}
+	src0, src1 = start(y0), start(y1-1)+filterLength
+	if src0 < 0 {
+		src0 = 0
+	}
+	if src1 > p.srcHeight {
+		src1 = p.srcHeight
+	}
+	return src0, src1
+}
+
+// newTiledResult returns an image of the size r for the tiles of img scaled
+// with interp, of the type that Resize returns for img, or a writable one of
+// the same precision.
+func newTiledResult(img image.Image, r image.Rectangle, interp InterpolationFunction) image.Image {
+	if interp == NearestNeighbor {
+		// resizeNearest keeps straight alpha and reads all images without
+		// a specialized path, e.g. CMYK, in 16-bit precision.
+		switch img.(type) {
+		case *image.NRGBA:
+			return image.NewNRGBA(r)
+		case *image.NRGBA64:
+			return image.NewNRGBA64(r)
+		case *image.RGBA, *image.YCbCr, *image.RGBA64, *image.Gray, *image.Gray16, *image.Alpha, *image.Alpha16:
+		default:
+			return image.NewRGBA64(r)
+		}
+	}
+	switch img.(type) {
+	case *image.RGBA, *image.NRGBA, *image.CMYK:
+		return image.NewRGBA(r)
+	case *image.YCbCr:
+		return image.NewYCbCr(r, image.YCbCrSubsampleRatio444)
+	case *image.RGBA64, *image.NRGBA64:
+		return image.NewRGBA64(r)
+	case *image.Gray:
+		return image.NewGray(r)
+	case *image.Gray16:
+		return image.NewGray16(r)
+	case *image.Alpha:
+		return image.NewAlpha(r)
+	case *image.Alpha16:
+		return image.NewAlpha16(r)
+	}
+	if img.ColorModel() == color.NRGBAModel {
+		return image.NewNRGBA64(r)
+	}
+	return image.NewRGBA64(r)
+}
+
+// copyTile copies tile, which has its origin at (0,0), to the region r of
+// result, as returned by newTiledResult.
+func copyTile(result image.Image, r image.Rectangle, tile image.Image) {
+	if dst, ok := result.(*image.YCbCr); ok {
+		src := tile.(*image.YCbCr)
+		for y := 0; y < r.Dy(); y++ {
+			do := dst.YOffset(r.Min.X, r.Min.Y+y)
+			so := src.YOffset(0, y)
+			copy(dst.Y[do:do+r.Dx()], src.Y[so:so+r.Dx()])
+			copy(dst.Cb[do:do+r.Dx()], src.Cb[so:so+r.Dx()])
+			copy(dst.Cr[do:do+r.Dx()], src.Cr[so:so+r.Dx()])
+		}
+		return
+	}
+	draw.Draw(result.(draw.Image), r, tile, image.ZP, draw.Src)
+}
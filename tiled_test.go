@@ -0,0 +1,204 @@
+package resize
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func Test_ResizeTiledSchedule(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 90, 70))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	gray := image.NewGray16(image.Rect(5, 5, 65, 105))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 13)
+	}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 90, 70))
+	for i := range nrgba.Pix {
+		nrgba.Pix[i] = uint8(i * 7)
+	}
+	cmyk := image.NewCMYK(image.Rect(0, 0, 90, 70))
+	for i := range cmyk.Pix {
+		cmyk.Pix[i] = uint8(i * 11)
+	}
+	ycc := image.NewYCbCr(image.Rect(0, 0, 64, 40), image.YCbCrSubsampleRatio420)
+	for i := range ycc.Y {
+		ycc.Y[i] = uint8(i * 3)
+	}
+	for i := range ycc.Cb {
+		ycc.Cb[i], ycc.Cr[i] = uint8(i*5), uint8(i*11)
+	}
+
+	for _, img := range []image.Image{rgba, gray, ycc, nrgba, cmyk} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+			for _, size := range []image.Point{{30, 20}, {130, 150}} {
+				// Run the tiles in random order, some of them concurrently.
+				var tiles []func()
+				m := ResizeTiledSchedule(uint(size.X), uint(size.Y), 16, 8, img, interp, func(tile func()) {
+					tiles = append(tiles, tile)
+				})
+				var wg sync.WaitGroup
+				for _, i := range rand.Perm(len(tiles)) {
+					wg.Add(1)
+					go func(tile func()) {
+						defer wg.Done()
+						tile()
+					}(tiles[i])
+				}
+				wg.Wait()
+
+				want := Resize(uint(size.X), uint(size.Y), img, interp)
+				if m.Bounds() != want.Bounds() {
+					t.Fatalf("%T: want bounds %v, got %v", img, want.Bounds(), m.Bounds())
+				}
+				for y := 0; y < size.Y; y++ {
+					for x := 0; x < size.X; x++ {
+						if g, w := color.RGBA64Model.Convert(m.At(x, y)), color.RGBA64Model.Convert(want.At(x, y)); g != w {
+							t.Fatalf("%T %v interp %v: pixel (%d,%d): want %v, got %v", img, size, interp, x, y, w, g)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_ResizeTiledScheduleBoundedSource(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 40, 400))
+	tiles := 0
+	ResizeTiledSchedule(20, 200, 20, 10, img, Bilinear, func(tile func()) {
+		tiles++
+		tile()
+	})
+	if tiles != 20 {
+		t.Errorf("want 20 tiles, got %d", tiles)
+	}
+
+	p := newPlan(40, 400, 20, 200, 2, 2, Bilinear)
+	if src0, src1 := p.srcRows(100, 110); src1-src0 > 30 {
+		t.Errorf("a tile of 10 rows reads %d source rows", src1-src0)
+	}
+}
+
+// countingImage counts the reads of its pixels and has no SubImage method.
+type countingImage struct {
+	gray  *image.Gray
+	reads *int64
+}
+
+func (p countingImage) ColorModel() color.Model { return p.gray.ColorModel() }
+func (p countingImage) Bounds() image.Rectangle { return p.gray.Bounds() }
+func (p countingImage) At(x, y int) color.Color {
+	atomic.AddInt64(p.reads, 1)
+	return p.gray.At(x, y)
+}
+
+func Test_ResizeTiledScheduleGenericSource(t *testing.T) {
+	gray := image.NewGray(image.Rect(0, 0, 40, 400))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 7)
+	}
+	img := countingImage{gray, new(int64)}
+	want := Resize(20, 200, img, Bilinear)
+	full := *img.reads
+
+	*img.reads = 0
+	m := ResizeTiledSchedule(20, 200, 20, 10, img, Bilinear, func(tile func()) {
+		tile()
+	})
+	// Each tile reads its own source rows, not the whole source.
+	if *img.reads > 2*full {
+		t.Errorf("tiles read %d pixels, a full resize %d", *img.reads, full)
+	}
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 20; x++ {
+			if g, w := color.RGBA64Model.Convert(m.At(x, y)), color.RGBA64Model.Convert(want.At(x, y)); g != w {
+				t.Fatalf("pixel (%d,%d): want %v, got %v", x, y, w, g)
+			}
+		}
+	}
+}
+
+func Test_ResizeToTileWriter(t *testing.T) {
+	img := image.NewRGBA(image.Rect(3, 4, 93, 74))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+
+	for _, size := range []image.Point{{30, 20}, {130, 150}, {90, 70}} {
+		want := Resize(uint(size.X), uint(size.Y), img, Lanczos3)
+		got := image.NewRGBA64(image.Rect(0, 0, size.X, size.Y))
+		tiles := 0
+		err := ResizeToTileWriter(uint(size.X), uint(size.Y), 16, 8, img, Lanczos3, func(tx, ty int, tile image.Image) error {
+			if tile.Bounds().Min != image.ZP {
+				t.Fatalf("tile (%d,%d) has bounds %v", tx, ty, tile.Bounds())
+			}
+			r := tile.Bounds().Add(image.Pt(tx*16, ty*8))
+			draw.Draw(got, r, tile, image.ZP, draw.Src)
+			tiles++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := ((size.X + 15) / 16) * ((size.Y + 7) / 8); tiles != n {
+			t.Errorf("%v: want %d tiles, got %d", size, n, tiles)
+		}
+		b := want.Bounds()
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				if g, w := got.At(x, y), color.RGBA64Model.Convert(want.At(x+b.Min.X, y+b.Min.Y)); g != w {
+					t.Fatalf("%v: pixel (%d,%d): want %v, got %v", size, x, y, w, g)
+				}
+			}
+		}
+	}
+
+	stop := errors.New("stop")
+	tiles := 0
+	err := ResizeToTileWriter(30, 20, 16, 8, img, Bilinear, func(tx, ty int, tile image.Image) error {
+		tiles++
+		return stop
+	})
+	if err != stop || tiles != 1 {
+		t.Errorf("want error %v after 1 tile, got %v after %d", stop, err, tiles)
+	}
+}
+
+func Test_ResizeTiled(t *testing.T) {
+	rgba := image.NewRGBA(image.Rect(0, 0, 90, 300))
+	for i := range rgba.Pix {
+		rgba.Pix[i] = uint8(i * 7)
+	}
+	gray16 := image.NewGray16(image.Rect(5, 5, 65, 305))
+	for i := range gray16.Pix {
+		gray16.Pix[i] = uint8(i * 13)
+	}
+
+	for _, img := range []image.Image{rgba, gray16} {
+		for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear, Lanczos3} {
+			// The results have one, several and a partial last band.
+			for _, size := range []image.Point{{30, 50}, {45, 150}, {20, 130}} {
+				m := ResizeTiled(uint(size.X), uint(size.Y), img, interp)
+				want := Resize(uint(size.X), uint(size.Y), img, interp)
+				if m.Bounds() != want.Bounds() {
+					t.Fatalf("%T: want bounds %v, got %v", img, want.Bounds(), m.Bounds())
+				}
+				for y := 0; y < size.Y; y++ {
+					for x := 0; x < size.X; x++ {
+						if g, w := color.RGBA64Model.Convert(m.At(x, y)), color.RGBA64Model.Convert(want.At(x, y)); g != w {
+							t.Fatalf("%T %v interp %v: pixel (%d,%d): want %v, got %v", img, size, interp, x, y, w, g)
+						}
+					}
+				}
+			}
+		}
+	}
+}
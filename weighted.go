@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2012, Jan Schlicht <jan.schlicht@gmail.com>
+
+Permission to use, copy, modify, and/or distribute this software for any purpose
+with or without fee is hereby granted, provided that the above copyright notice
+and this permission notice appear in all copies.
+
+THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES WITH
+REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF MERCHANTABILITY AND
+FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY SPECIAL, DIRECT,
+INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES WHATSOEVER RESULTING FROM LOSS
+OF USE, DATA OR PROFITS, WHETHER IN AN ACTION OF CONTRACT, NEGLIGENCE OR OTHER
+TORTIOUS ACTION, ARISING OUT OF OR IN CONNECTION WITH THE USE OR PERFORMANCE OF
+THIS SOFTWARE.
+*/
+
+package resize
+
+import (
+	"image"
+)
+
+// ResizeWeighted scales an image like Resize, but weights every source pixel
+// by its confidence in weights, e.g. for focus stacking or masked blending.
+// The taps of the kernel are multiplied by the confidence of their pixels and
+// renormalized, so pixels with a confidence of 0 contribute nothing and an
+// output pixel without any confident taps is transparent.
+// weights is aligned with the top left corner of img, pixels outside of its
+// bounds have a confidence of 0. The result is an *image.RGBA64.
+func ResizeWeighted(width, height uint, img image.Image, weights *image.Gray, interp InterpolationFunction) image.Image {
+	b := img.Bounds()
+	width, height, scaleX, scaleY := scaledSize(width, height, b.Dx(), b.Dy())
+
+	// Input image has no pixels
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return img
+	}
+
+	wb := weights.Bounds()
+	in := weightedFloatImage(img, func(x, y int) float32 {
+		p := image.Pt(x+wb.Min.X, y+wb.Min.Y)
+		if !p.In(wb) {
+			return 0
+		}
+		return float32(weights.Pix[weights.PixOffset(p.X, p.Y)]) / 0xff
+	})
+	out := resizeFloat(in, int(width), int(height), scaleX, scaleY, interp)
+
+	return normalizeWeighted(out, 1e-6).RGBA64()
+}
+
+// weightedFloatImage returns the colors of img premultiplied by the weight
+// of their pixel, which is kept in a fifth channel. Like in ResizeDepth, a
+// plain convolution followed by a division by the weight channel is then a
+// normalized convolution. weight is called with coordinates relative to the
+// top left corner of img.
+func weightedFloatImage(img image.Image, weight func(x, y int) float32) *floatImage {
+	src := newFloatImageFromImage(img)
+	in := newFloatImage(src.Rect, 5)
+	for y := 0; y < src.Rect.Dy(); y++ {
+		for x := 0; x < src.Rect.Dx(); x++ {
+			w := weight(x, y)
+			if w == 0 {
+				continue
+			}
+			i, o := src.PixOffset(x, y), in.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				in.Pix[o+c] = src.Pix[i+c] * w
+			}
+			in.Pix[o+4] = w
+		}
+	}
+	return in
+}
+
+// normalizeWeighted divides the colors of a resized weightedFloatImage by
+// their weight. Pixels with a weight below min are transparent.
+func normalizeWeighted(out *floatImage, min float32) *floatImage {
+	result := newFloatImage(out.Rect, 4)
+	for y := 0; y < out.Rect.Dy(); y++ {
+		for x := 0; x < out.Rect.Dx(); x++ {
+			o := out.PixOffset(x, y)
+			w := out.Pix[o+4]
+			if w < min {
+				continue
+			}
+			i := result.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				result.Pix[i+c] = out.Pix[o+c] / w
+			}
+		}
+	}
+	return result
+}
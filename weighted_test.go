@@ -0,0 +1,65 @@
+package resize
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func Test_ResizeWeighted(t *testing.T) {
+	// The left half is red with full confidence, the right half blue with
+	// little confidence.
+	img := image.NewRGBA(image.Rect(0, 0, 16, 4))
+	weights := image.NewGray(img.Bounds())
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 16; x++ {
+			if x < 8 {
+				img.Set(x, y, color.RGBA{0xff, 0, 0, 0xff})
+				weights.SetGray(x, y, color.Gray{0xff})
+			} else {
+				img.Set(x, y, color.RGBA{0, 0, 0xff, 0xff})
+				weights.SetGray(x, y, color.Gray{0x10})
+			}
+		}
+	}
+
+	m := ResizeWeighted(1, 1, img, weights, Bilinear)
+	r, _, b, a := m.At(0, 0).RGBA()
+	if r < 10*b {
+		t.Errorf("red %d does not dominate blue %d", r, b)
+	}
+	if a != 0xffff {
+		t.Errorf("want opaque result, got alpha %d", a)
+	}
+
+	// Zero confidence removes a region completely.
+	for y := 0; y < 4; y++ {
+		for x := 8; x < 16; x++ {
+			weights.SetGray(x, y, color.Gray{0})
+		}
+	}
+	m = ResizeWeighted(4, 1, img, weights, Bilinear)
+	for x := 0; x < 4; x++ {
+		if _, _, b, _ := m.At(x, 0).RGBA(); b != 0 {
+			t.Errorf("pixel %d: blue %d leaks from zero confidence pixels", x, b)
+		}
+	}
+
+	// Uniform confidence is an ordinary resize.
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 16; x++ {
+			weights.SetGray(x, y, color.Gray{0x80})
+		}
+	}
+	m = ResizeWeighted(8, 2, img, weights, Bilinear)
+	want := resizeFloat(newFloatImageFromImage(img), 8, 2, 2, 2, Bilinear).RGBA64()
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 8; x++ {
+			r0, g0, b0, a0 := m.At(x, y).RGBA()
+			r1, g1, b1, a1 := want.At(x, y).RGBA()
+			if absDiff(r0, r1) > 1 || absDiff(g0, g1) > 1 || absDiff(b0, b1) > 1 || absDiff(a0, a1) > 1 {
+				t.Errorf("pixel (%d,%d): want %v, got %v", x, y, want.At(x, y), m.At(x, y))
+			}
+		}
+	}
+}
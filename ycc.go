@@ -121,6 +121,8 @@ func (p *ycc) YCbCr() *image.YCbCr {
 }
 
 // imageYCbCrToYCC converts a YCbCr image to a ycc image for resizing.
+// The chroma sample of a pixel is found from its absolute position, as in
+// COffset, so that sub-images with an odd origin keep their colors.
 func imageYCbCrToYCC(in *image.YCbCr) *ycc {
 	w, h := in.Rect.Dx(), in.Rect.Dy()
 	p := ycc{
@@ -276,7 +278,7 @@ func convertToYCC422(in *image.YCbCr, p *ycc) *ycc {
 		yy := y * in.YStride
 		cy := y * in.CStride
 		for x := 0; x < in.Rect.Max.X-in.Rect.Min.X; x++ {
-			ci := cy + x/2
+			ci := cy + (in.Rect.Min.X+x)/2 - in.Rect.Min.X/2
 			Pix[off+0] = Y[yy+x]
 			Pix[off+1] = Cb[ci]
 			Pix[off+2] = Cr[ci]
@@ -294,9 +296,9 @@ func convertToYCC420(in *image.YCbCr, p *ycc) *ycc {
 	Cr := in.Cr
 	for y := 0; y < in.Rect.Max.Y-in.Rect.Min.Y; y++ {
 		yy := y * in.YStride
-		cy := (y / 2) * in.CStride
+		cy := ((in.Rect.Min.Y+y)/2 - in.Rect.Min.Y/2) * in.CStride
 		for x := 0; x < in.Rect.Max.X-in.Rect.Min.X; x++ {
-			ci := cy + x/2
+			ci := cy + (in.Rect.Min.X+x)/2 - in.Rect.Min.X/2
 			Pix[off+0] = Y[yy+x]
 			Pix[off+1] = Cb[ci]
 			Pix[off+2] = Cr[ci]
@@ -314,7 +316,7 @@ func convertToYCC440(in *image.YCbCr, p *ycc) *ycc {
 	Cr := in.Cr
 	for y := 0; y < in.Rect.Max.Y-in.Rect.Min.Y; y++ {
 		yy := y * in.YStride
-		cy := (y / 2) * in.CStride
+		cy := ((in.Rect.Min.Y+y)/2 - in.Rect.Min.Y/2) * in.CStride
 		for x := 0; x < in.Rect.Max.X-in.Rect.Min.X; x++ {
 			ci := cy + x
 			Pix[off+0] = Y[yy+x]
@@ -356,7 +358,7 @@ func convertToYCC411(in *image.YCbCr, p *ycc) *ycc {
 		yy := y * in.YStride
 		cy := y * in.CStride
 		for x := 0; x < in.Rect.Max.X-in.Rect.Min.X; x++ {
-			ci := cy + x/4
+			ci := cy + (in.Rect.Min.X+x)/4 - in.Rect.Min.X/4
 			Pix[off+0] = Y[yy+x]
 			Pix[off+1] = Cb[ci]
 			Pix[off+2] = Cr[ci]
@@ -374,9 +376,9 @@ func convertToYCC410(in *image.YCbCr, p *ycc) *ycc {
 	Cr := in.Cr
 	for y := 0; y < in.Rect.Max.Y-in.Rect.Min.Y; y++ {
 		yy := y * in.YStride
-		cy := (y / 2) * in.CStride
+		cy := ((in.Rect.Min.Y+y)/2 - in.Rect.Min.Y/2) * in.CStride
 		for x := 0; x < in.Rect.Max.X-in.Rect.Min.X; x++ {
-			ci := cy + x/4
+			ci := cy + (in.Rect.Min.X+x)/4 - in.Rect.Min.X/4
 			Pix[off+0] = Y[yy+x]
 			Pix[off+1] = Cb[ci]
 			Pix[off+2] = Cr[ci]
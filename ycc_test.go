@@ -21,6 +21,7 @@ package resize
 import (
 	"image"
 	"image/color"
+	"reflect"
 	"testing"
 )
 
@@ -217,3 +218,44 @@ func testYCbCr(t *testing.T, r image.Rectangle, subsampleRatio image.YCbCrSubsam
 		}
 	}
 }
+
+func Test_ResizeYCbCrSubImage(t *testing.T) {
+	ratios := []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio440,
+		image.YCbCrSubsampleRatio411,
+		image.YCbCrSubsampleRatio410,
+	}
+	for _, ratio := range ratios {
+		full := image.NewYCbCr(image.Rect(0, 0, 40, 30), ratio)
+		for i := range full.Y {
+			full.Y[i] = uint8(i * 7)
+		}
+		for i := range full.Cb {
+			full.Cb[i], full.Cr[i] = uint8(i*29), uint8(i*53)
+		}
+
+		for _, r := range []image.Rectangle{image.Rect(1, 1, 38, 29), image.Rect(3, 2, 40, 27), image.Rect(2, 3, 33, 30)} {
+			sub := full.SubImage(r).(*image.YCbCr)
+			// The reference has full chroma from At and its origin at (0,0).
+			ref := image.NewYCbCr(image.Rect(0, 0, r.Dx(), r.Dy()), image.YCbCrSubsampleRatio444)
+			for y := 0; y < r.Dy(); y++ {
+				for x := 0; x < r.Dx(); x++ {
+					c := sub.At(r.Min.X+x, r.Min.Y+y).(color.YCbCr)
+					ref.Y[ref.YOffset(x, y)] = c.Y
+					ref.Cb[ref.COffset(x, y)] = c.Cb
+					ref.Cr[ref.COffset(x, y)] = c.Cr
+				}
+			}
+
+			for _, interp := range []InterpolationFunction{NearestNeighbor, Bilinear} {
+				got := Resize(15, 11, sub, interp)
+				if want := Resize(15, 11, ref, interp); !reflect.DeepEqual(got, want) {
+					t.Errorf("%v %v interp %v: differs from the reference", ratio, r, interp)
+				}
+			}
+		}
+	}
+}